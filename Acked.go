@@ -0,0 +1,260 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// defaultMaxOutstanding is the number of unacknowledged deliveries of Acked
+// without WithMaxOutstanding
+const defaultMaxOutstanding = 64
+
+var (
+	// ErrNacked is the error of a delivery rejected with Nack(nil)
+	ErrNacked = errors.New("async: delivery rejected")
+	// ErrAckTimeout is the error of a delivery that was not acknowledged
+	// within the timeout set with WithAckTimeout
+	ErrAckTimeout = errors.New("async: delivery not acknowledged in time")
+)
+
+// Delivery is an item emitted by Acked. The consumer confirms it with Ack
+// once it was processed or rejects it with Nack, which redelivers it.
+type Delivery[T any] struct {
+	Value T
+	// Attempt is the number of the delivery of the value, starting at 1
+	Attempt int
+	handle  *deliveryHandle
+}
+
+// Ack confirms that the value was processed. Only the first call of Ack or
+// Nack on a delivery counts. It has no effect on the zero Delivery of an
+// error item.
+func (d Delivery[T]) Ack() {
+	d.handle.settle(nil)
+}
+
+// Nack rejects the value, it is delivered again unless its retries are used
+// up. A nil err is replaced with ErrNacked. Only the first call of Ack or Nack
+// on a delivery counts. It has no effect on the zero Delivery of an error
+// item.
+func (d Delivery[T]) Nack(err error) {
+	if err == nil {
+		err = ErrNacked
+	}
+	d.handle.settle(err)
+}
+
+// deliveryHandle reports the outcome of a delivery to Acked
+type deliveryHandle struct {
+	once   sync.Once
+	id     uint64
+	events chan<- ackEvent
+	done   <-chan struct{}
+}
+
+func (h *deliveryHandle) settle(err error) {
+	if h == nil {
+		return
+	}
+	h.once.Do(func() {
+		select {
+		case h.events <- ackEvent{id: h.id, err: err}:
+		case <-h.done:
+		}
+	})
+}
+
+type ackEvent struct {
+	id  uint64
+	err error
+}
+
+// pendingDelivery is a value that awaits its acknowledgement or redelivery
+type pendingDelivery[T any] struct {
+	value    T
+	attempt  int
+	deadline time.Time
+}
+
+// Acked turns a sequence into a minimal at-least-once work queue: every value
+// is emitted as Delivery, which the consumer has to acknowledge. A value that
+// is rejected with Nack, or not acknowledged within the timeout set with
+// WithAckTimeout, is emitted again, up to maxRetries times. Afterwards it is
+// passed to the callback set with WithDeadLetter, together with the last
+// error, and dropped.
+//
+// At most 64 deliveries, or as many as set with WithMaxOutstanding, are
+// awaiting their acknowledgement at a time, Acked does not read the input
+// while the limit is reached. Redeliveries are emitted before new values.
+// Error items of the input are forwarded without tracking, their Delivery
+// is the zero value.
+//
+// The returned sequence is closed when the input is closed and all
+// deliveries were settled, or when the context is done. Then all outstanding
+// deliveries are released, a late Ack or Nack has no effect.
+//
+// Supported options:
+//   - WithAckTimeout
+//   - WithClock
+//   - WithDeadLetter
+//   - WithMaxOutstanding
+//
+// Example:
+//
+//	jobs := Acked(ctx, Stream(ctx, nextJob), 3, WithAckTimeout(time.Minute), WithDeadLetter(func(v any, err error) {
+//	    log.Printf("giving up on %v: %v", v, err)
+//	}))
+//	for result := range jobs {
+//	    if result.Error != nil {
+//	        log.Printf("reading jobs failed: %v", result.Error)
+//	        continue
+//	    }
+//	    d := result.Value
+//	    if err := process(d.Value); err != nil {
+//	        d.Nack(err)
+//	        continue
+//	    }
+//	    d.Ack()
+//	}
+func Acked[T any](ctx context.Context, in Sequence[T], maxRetries int, opts ...Option) Sequence[Delivery[T]] {
+	o := newOptions(opts)
+	limit := o.maxOutstanding
+	if limit <= 0 {
+		limit = defaultMaxOutstanding
+	}
+	r := make(Sequence[Delivery[T]])
+	spawn("Acked", o, func() {
+		defer close(r)
+		a := &acker[T]{
+			o:           o,
+			maxRetries:  max(maxRetries, 0),
+			outstanding: map[uint64]*pendingDelivery[T]{},
+			events:      make(chan ackEvent),
+			done:        make(chan struct{}),
+		}
+		defer close(a.done)
+		a.run(ctx, in, r, limit)
+	})
+	return r
+}
+
+// acker tracks the deliveries of Acked
+type acker[T any] struct {
+	o           *options
+	maxRetries  int
+	lastID      uint64
+	outstanding map[uint64]*pendingDelivery[T]
+	redeliver   []*pendingDelivery[T]
+	events      chan ackEvent
+	done        chan struct{}
+}
+
+func (a *acker[T]) run(ctx context.Context, in Sequence[T], r Sequence[Delivery[T]], limit int) {
+	var timer Timer
+	if a.o.ackTimeout > 0 {
+		timer = a.o.clock.NewTimer(a.o.ackTimeout)
+		defer timer.Stop()
+	}
+	// item is the next item to emit, next the value it delivers unless it is
+	// a forwarded error
+	var item *_Result[Delivery[T]]
+	var next *pendingDelivery[T]
+	for {
+		if item == nil && len(a.redeliver) > 0 {
+			next, a.redeliver = a.redeliver[0], a.redeliver[1:]
+			item = a.delivery(next)
+		}
+		if in == nil && item == nil && len(a.outstanding) == 0 {
+			return
+		}
+		// the input is only read if there is nothing else to emit and room
+		// for another delivery
+		var input Sequence[T]
+		var out Sequence[Delivery[T]]
+		var emit _Result[Delivery[T]]
+		if item != nil {
+			out, emit = r, *item
+		} else if len(a.outstanding) < limit {
+			input = in
+		}
+		var expired <-chan time.Time
+		if timer != nil && len(a.outstanding) > 0 {
+			timer.Reset(max(a.earliest().Sub(a.o.clock.Now()), 0))
+			expired = timer.C()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case value, ok := <-input:
+			switch {
+			case !ok:
+				in = nil
+			case value.Error != nil:
+				item = &_Result[Delivery[T]]{Error: value.Error}
+			default:
+				next = &pendingDelivery[T]{value: value.Value}
+				item = a.delivery(next)
+			}
+		case out <- emit:
+			if next != nil {
+				if a.o.ackTimeout > 0 {
+					next.deadline = a.o.clock.Now().Add(a.o.ackTimeout)
+				}
+				a.outstanding[a.lastID] = next
+			}
+			item, next = nil, nil
+		case e := <-a.events:
+			if p, ok := a.outstanding[e.id]; ok {
+				delete(a.outstanding, e.id)
+				if e.err != nil {
+					a.retry(p, e.err)
+				}
+			}
+		case <-expired:
+			now := a.o.clock.Now()
+			for id, p := range a.outstanding {
+				if !now.Before(p.deadline) {
+					delete(a.outstanding, id)
+					a.retry(p, ErrAckTimeout)
+				}
+			}
+		}
+	}
+}
+
+// delivery prepares the next delivery of a value under a new ID
+func (a *acker[T]) delivery(p *pendingDelivery[T]) *_Result[Delivery[T]] {
+	p.attempt++
+	a.lastID++
+	d := Success(Delivery[T]{
+		Value:   p.value,
+		Attempt: p.attempt,
+		handle:  &deliveryHandle{id: a.lastID, events: a.events, done: a.done},
+	})
+	return &d
+}
+
+// retry queues a failed delivery again or passes it to the dead letter
+// callback
+func (a *acker[T]) retry(p *pendingDelivery[T], err error) {
+	if p.attempt <= a.maxRetries {
+		a.redeliver = append(a.redeliver, p)
+		return
+	}
+	if a.o.deadLetter != nil {
+		a.o.deadLetter(p.value, err)
+	}
+}
+
+// earliest returns the earliest deadline of the outstanding deliveries
+func (a *acker[T]) earliest() time.Time {
+	var first time.Time
+	for _, p := range a.outstanding {
+		if first.IsZero() || p.deadline.Before(first) {
+			first = p.deadline
+		}
+	}
+	return first
+}
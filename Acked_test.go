@@ -0,0 +1,64 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestAckedRedeliversNackedValues(t *testing.T) {
+	ctx := context.Background()
+	var dead []any
+	jobs := async.Acked(ctx, seqOf("a", "b"), 1, async.WithDeadLetter(func(v any, err error) {
+		if !errors.Is(err, errDownstream) {
+			t.Errorf("want the last error passed to the dead letter, got %v", err)
+		}
+		dead = append(dead, v)
+	}))
+
+	var got []string
+	for result := range jobs {
+		if result.Error != nil {
+			t.Fatalf("want no error items, got %v", result.Error)
+		}
+		d := result.Value
+		got = append(got, d.Value)
+		if d.Value == "b" {
+			d.Nack(errDownstream)
+			continue
+		}
+		d.Ack()
+	}
+	if want := []string{"a", "b", "b"}; !slices.Equal(got, want) {
+		t.Fatalf("want deliveries %v, got %v", want, got)
+	}
+	if !slices.Equal(dead, []any{"b"}) {
+		t.Fatalf("want b dead lettered, got %v", dead)
+	}
+}
+
+func TestAckedForwardsErrorItems(t *testing.T) {
+	ctx := context.Background()
+	in := make(async.Sequence[int], 2)
+	in <- async.Fail[int](errDownstream)
+	in <- async.Success(1)
+	close(in)
+
+	var errs []error
+	for result := range async.Acked(ctx, in, 0) {
+		if result.Error != nil {
+			errs = append(errs, result.Error)
+			// settling the zero delivery of an error item has no effect
+			result.Value.Ack()
+			result.Value.Nack(nil)
+			continue
+		}
+		result.Value.Ack()
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the error item forwarded, got %v", errs)
+	}
+}
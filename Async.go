@@ -2,6 +2,8 @@ package async
 
 import (
 	"context"
+
+	"github.com/uoul/go-async/internal/registry"
 )
 
 // Do executes the given action asynchronously in a goroutine and returns
@@ -17,7 +19,20 @@ import (
 // If the action returns an error, the channel receives a _Result[T] with a
 // non-nil Error field and a zero-value Value field. If the action succeeds,
 // the channel receives a _Result[T] with the result in the Value field and
-// a nil Error field.
+// a nil Error field. If the action returns ctx.Err() of a context that was
+// cancelled with a cause, the error is a *CancelError carrying the cause.
+//
+// Supported options:
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRecover
+//   - WithTracer
+//   - WithTracker
 //
 // Example:
 //
@@ -30,18 +45,67 @@ import (
 //	} else {
 //	    log.Printf("Success: %v", r.Value)
 //	}
-func Do[T any](ctx context.Context, action func(ctx context.Context) (T, error)) Result[T] {
-	r := make(Result[T])
-	go func() {
-		defer close(r)
-		result, err := action(ctx)
-		if err != nil {
-			r <- Fail[T](err)
-		} else {
-			r <- Success[T](result)
-		}
-	}()
-	return r
+func Do[T any](ctx context.Context, action func(ctx context.Context) (T, error), opts ...Option) Result[T] {
+	o := newOptions(opts)
+	return doFinally(ctx, o, callSite(o), action, nil)
+}
+
+// doFinally is Do with the options already built. If finally is not nil, it
+// is called on the goroutine of the task once the action settled, before the
+// result is sent, whether the action was executed or not, e.g. because an
+// interceptor short-circuited it or no concurrency slot was granted.
+func doFinally[T any](ctx context.Context, o *options, site []uintptr, action func(ctx context.Context) (T, error), finally func()) Result[T] {
+	t := &doTask[T]{parent: ctx, o: o, site: site, action: action, finally: finally, r: make(Result[T], 1)}
+	spawnEntry(string(kindDo), o, &t.e, t.run)
+	return t.r
+}
+
+// doDeliver is doFinally for the variants of Do that hand the outcome on in a
+// shape of their own, like DoVoid and Do2: deliver receives it on the
+// goroutine of the task in place of a Result. On error, v is the zero value.
+func doDeliver[T any](ctx context.Context, o *options, site []uintptr, kind string, action func(ctx context.Context) (T, error), deliver func(v T, err error)) {
+	t := &doTask[T]{parent: ctx, o: o, site: site, action: action, deliver: deliver}
+	spawnEntry(kind, o, &t.e, t.run)
+}
+
+// doTask is the state of the goroutine of a Do. It embeds the taskRun, so
+// that on the success path a Do allocates only the task, the function value
+// of run and the result channel, whose buffer holding the error interface is
+// allocated by the runtime apart from the channel itself.
+type doTask[T any] struct {
+	task    taskRun
+	parent  context.Context
+	o       *options
+	site    []uintptr
+	action  func(ctx context.Context) (T, error)
+	finally func()
+	// either r or deliver receives the outcome
+	r       Result[T]
+	deliver func(v T, err error)
+	e       *registry.Entry
+}
+
+func (t *doTask[T]) run() {
+	if t.r != nil {
+		defer close(t.r)
+	}
+	o := t.o
+	ctx := t.task.begin(t.parent, o, kindDo)
+	result, err := execute(ctx, o, t.action)
+	t.task.end(err)
+	if t.finally != nil {
+		t.finally()
+	}
+	t.e.SetState(stateSending)
+	if err != nil {
+		var zero T
+		result, err = zero, stackError(t.site, o.name, taskError(ctx, o.name, err))
+	}
+	if t.deliver != nil {
+		t.deliver(result, err)
+		return
+	}
+	t.r <- _Result[T]{Value: result, Error: err}
 }
 
 // Stream executes the given step function repeatedly in a goroutine and
@@ -64,6 +128,26 @@ func Do[T any](ctx context.Context, action func(ctx context.Context) (T, error))
 // over time, such as paginated API calls, database cursors, or iterative
 // computations.
 //
+// Supported options:
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithMaxDuration
+//   - WithMaxErrors
+//   - WithMaxIterations
+//   - WithName
+//   - WithOnClose
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRateLimit
+//   - WithRecover
+//   - WithSpanPerStep
+//   - WithStepTimeout
+//   - WithStopOnError
+//   - WithTracer
+//   - WithTracker
+//
 // Example:
 //
 //	seq := Stream(ctx, func(ctx context.Context) (int, error, bool) {
@@ -77,21 +161,13 @@ func Do[T any](ctx context.Context, action func(ctx context.Context) (T, error))
 //	    }
 //	    log.Printf("received: %v", result.Value)
 //	}
-func Stream[T any](ctx context.Context, step func(ctx context.Context) (T, error, bool)) Sequence[T] {
-	r := make(Sequence[T])
-	go func() {
+func Stream[T any](ctx context.Context, step func(ctx context.Context) (T, error, bool), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	site := callSite(o)
+	r := streamOut[T](o)
+	spawnTracked(string(kindStream), o, func(e *registry.Entry) {
 		defer close(r)
-		for {
-			result, err, next := step(ctx)
-			if err != nil {
-				r <- Fail[T](err)
-			} else {
-				r <- Success[T](result)
-			}
-			if !next {
-				break
-			}
-		}
-	}()
+		runStream(ctx, o, false, r, e, site, step)
+	})
 	return r
 }
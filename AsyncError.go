@@ -0,0 +1,77 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AsyncError wraps the failures of named tasks, i.e. tasks started with
+// WithName, with the metadata of the task. It gives error reporters a stable
+// shape to tell which task and which attempt failed, without parsing
+// messages.
+//
+// The errors of a named Do, DoVoid and Do2, the error items of the steps of a
+// named Stream and the final errors of a named RetryEach are wrapped.
+// errors.Is and errors.As see through it.
+type AsyncError struct {
+	// TaskName is the name given with WithName
+	TaskName string
+	// TaskID is the ID of the task, see TaskID. It is empty for stages that
+	// are no tasks of their own, such as RetryEach.
+	TaskID string
+	// Attempt is the number of the failed attempt, starting at 1. For a
+	// RetryEach it is the last attempt of the item. For the other tasks it is
+	// taken from a *RetryError the error wraps, e.g. of Pages with WithRetry,
+	// and is 1 if there is none.
+	Attempt int
+	// Err is the original error
+	Err error
+}
+
+func (e *AsyncError) Error() string {
+	if e.Attempt > 1 {
+		return fmt.Sprintf("async: task %q failed on attempt %d: %v", e.TaskName, e.Attempt, e.Err)
+	}
+	return fmt.Sprintf("async: task %q failed: %v", e.TaskName, e.Err)
+}
+
+func (e *AsyncError) Unwrap() error {
+	return e.Err
+}
+
+// AsAsyncError returns the *AsyncError in the chain of err, if there is one
+//
+// Example:
+//
+//	if aerr, ok := AsAsyncError(r.Error); ok {
+//	    log.Printf("task %s (%s) failed: %v", aerr.TaskName, aerr.TaskID, aerr.Err)
+//	}
+func AsAsyncError(err error) (*AsyncError, bool) {
+	var aerr *AsyncError
+	ok := errors.As(err, &aerr)
+	return aerr, ok
+}
+
+// asyncError wraps the error of a named task into an *AsyncError, the errors
+// of unnamed tasks are returned as they are. The task ID is taken from ctx.
+func asyncError(ctx context.Context, name string, attempt int, err error) error {
+	if name == "" || err == nil {
+		return err
+	}
+	return &AsyncError{TaskName: name, TaskID: TaskID(ctx), Attempt: attempt, Err: err}
+}
+
+// taskError is asyncError for the error of an action or step. The attempt is
+// the one of a *RetryError in the chain of err, otherwise 1.
+func taskError(ctx context.Context, name string, err error) error {
+	if name == "" || err == nil {
+		return err
+	}
+	attempt := 1
+	var rerr *RetryError
+	if errors.As(err, &rerr) {
+		attempt = rerr.Attempts
+	}
+	return asyncError(ctx, name, attempt, err)
+}
@@ -0,0 +1,78 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestAsyncErrorDo(t *testing.T) {
+	errFailed := errors.New("failed")
+	var id string
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		id = async.TaskID(ctx)
+		return 0, errFailed
+	}, async.WithName("fetch"))
+	aerr, ok := async.AsAsyncError(r.Error)
+	if !ok {
+		t.Fatalf("want an *AsyncError, got %#v", r.Error)
+	}
+	if want := (async.AsyncError{TaskName: "fetch", TaskID: id, Attempt: 1, Err: errFailed}); *aerr != want {
+		t.Fatalf("want %+v, got %+v", want, *aerr)
+	}
+	if !errors.Is(r.Error, errFailed) {
+		t.Fatalf("want the original error unwrapped, got %v", r.Error)
+	}
+	if want := `async: task "fetch" failed: failed`; r.Error.Error() != want {
+		t.Fatalf("want %q, got %q", want, r.Error.Error())
+	}
+}
+
+func TestAsyncErrorUnnamed(t *testing.T) {
+	errFailed := errors.New("failed")
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errFailed
+	})
+	if _, ok := async.AsAsyncError(r.Error); ok || r.Error != errFailed {
+		t.Fatalf("want the error of an unnamed task as it is, got %#v", r.Error)
+	}
+}
+
+func TestAsyncErrorStreamAttempt(t *testing.T) {
+	errFailed := errors.New("failed")
+	calls := 0
+	users := async.Pages(context.Background(), "", func(ctx context.Context, cursor string) ([]string, string, bool, error) {
+		calls++
+		return nil, "", false, errFailed
+	}, async.WithName("users"), async.WithRetry(3, nil))
+	_, errs := items(users)
+	if len(errs) != 1 || calls != 3 {
+		t.Fatalf("want a single error after 3 calls, got %v after %d", errs, calls)
+	}
+	aerr, ok := async.AsAsyncError(errs[0])
+	if !ok || aerr.TaskName != "users" || aerr.TaskID == "" || aerr.Attempt != 3 {
+		t.Fatalf("want the last attempt of the named step, got %#v", errs[0])
+	}
+	var rerr *async.RetryError
+	if !errors.As(errs[0], &rerr) || !errors.Is(errs[0], errFailed) {
+		t.Fatalf("want the *RetryError unwrapped, got %v", errs[0])
+	}
+	if want := `async: task "users" failed on attempt 3: async: failed after 3 attempts: failed`; errs[0].Error() != want {
+		t.Fatalf("want %q, got %q", want, errs[0].Error())
+	}
+}
+
+func TestAsyncErrorStreamStep(t *testing.T) {
+	errFailed := errors.New("failed")
+	_, errs := items(async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		return 0, errFailed, false
+	}, async.WithName("poll")))
+	if len(errs) != 1 {
+		t.Fatalf("want a single error, got %v", errs)
+	}
+	if aerr, ok := async.AsAsyncError(errs[0]); !ok || aerr.Attempt != 1 || aerr.Err != errFailed {
+		t.Fatalf("want the error of a step executed once, got %#v", errs[0])
+	}
+}
@@ -0,0 +1,155 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// seqOf returns a closed sequence of the given values
+func seqOf[T any](values ...T) async.Sequence[T] {
+	s := make(async.Sequence[T], len(values))
+	for _, v := range values {
+		s <- async.Success(v)
+	}
+	close(s)
+	return s
+}
+
+// eventually waits until cond holds
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// BenchmarkDo measures the success path of Do, which allocates 4 times per
+// call: the state of the task, the function value passed to the runner and
+// the result channel, which the runtime allocates in two parts because its
+// buffer holds an error interface.
+func BenchmarkDo(b *testing.B) {
+	ctx := context.Background()
+	action := func(ctx context.Context) (int, error) {
+		return 1, nil
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		<-async.Do(ctx, action)
+	}
+}
+
+func BenchmarkDoError(b *testing.B) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	action := func(ctx context.Context) (int, error) {
+		return 0, errFailed
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		<-async.Do(ctx, action)
+	}
+}
+
+// counter is a step function that counts from 1 and never stops
+func counter() func(ctx context.Context) (int, error, bool) {
+	n := 0
+	return func(ctx context.Context) (int, error, bool) {
+		n++
+		return n, nil, true
+	}
+}
+
+// items splits the items of a sequence into values and errors
+func items[T any](seq async.Sequence[T]) ([]T, []error) {
+	var values []T
+	var errs []error
+	for item := range seq {
+		if item.Error != nil {
+			errs = append(errs, item.Error)
+		} else {
+			values = append(values, item.Value)
+		}
+	}
+	return values, errs
+}
+
+func TestStreamMaxIterations(t *testing.T) {
+	values, errs := items(async.Stream(context.Background(), counter(), async.WithMaxIterations(3)))
+	if !slices.Equal(values, []int{1, 2, 3}) {
+		t.Fatalf("want 3 values, got %v", values)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], async.ErrMaxIterations) {
+		t.Fatalf("want a final ErrMaxIterations item, got %v", errs)
+	}
+}
+
+func TestStreamMaxIterationsNotReached(t *testing.T) {
+	n := 0
+	seq := async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		return n, nil, n < 3
+	}, async.WithMaxIterations(3))
+	values, errs := items(seq)
+	if !slices.Equal(values, []int{1, 2, 3}) || len(errs) != 0 {
+		t.Fatalf("want 3 values without error when the step stops at the cap, got %v and %v", values, errs)
+	}
+}
+
+func TestStreamMaxIterationsUnlimited(t *testing.T) {
+	ctx := context.Background()
+	for _, n := range []int{0, -1} {
+		seq := async.Take(ctx, async.Stream(ctx, counter(), async.WithMaxIterations(n)), 100)
+		if values, errs := items(seq); len(values) != 100 || len(errs) != 0 {
+			t.Fatalf("want an unlimited stream for a cap of %d, got %d values and %v", n, len(values), errs)
+		}
+	}
+}
+
+func TestStreamMaxIterationsWithStopOnError(t *testing.T) {
+	n := 0
+	seq := async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		if n == 2 {
+			return 0, errDownstream, true
+		}
+		return n, nil, true
+	}, async.WithMaxIterations(5), async.WithStopOnError())
+	values, errs := items(seq)
+	if !slices.Equal(values, []int{1}) || len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the stream stopped by the error before the cap, got %v and %v", values, errs)
+	}
+
+	// the last allowed step fails: the stream stops on the error, the cap
+	// is not reported in addition
+	n = 0
+	seq = async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		if n == 2 {
+			return 0, errDownstream, true
+		}
+		return n, nil, true
+	}, async.WithMaxIterations(2), async.WithStopOnError())
+	if _, errs := items(seq); len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want only the step error, got %v", errs)
+	}
+}
+
+func TestStreamMaxIterationsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seq := async.StreamState(ctx, 0, func(ctx context.Context, n int) (int, int, error, bool) {
+		return n + 1, n, nil, true
+	}, async.WithMaxIterations(1000))
+	<-seq
+	cancel()
+	asynctest.Drained(t, context.Background(), seq)
+}
@@ -0,0 +1,28 @@
+package async
+
+import (
+	"time"
+)
+
+// BackoffFunc returns the delay to wait after the given failed attempt before
+// the next one is started. Attempts are counted starting at 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ConstantBackoff waits the same delay after every failed attempt
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the delay after every failed attempt, starting
+// with initial and never exceeding max.
+func ExponentialBackoff(initial, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := initial
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
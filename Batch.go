@@ -0,0 +1,67 @@
+package async
+
+import (
+	"context"
+)
+
+// Batch groups the successful items of the input into slices of up to n
+// values. A batch is emitted once it is full and when the input is closed
+// with a partial batch. An error item emits the partial batch collected so
+// far and is forwarded after it, so the order of values and errors is kept.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done. A partial batch is dropped if the context is done.
+//
+// Supported options:
+//   - WithName
+//
+// Example:
+//
+//	for result := range Batch(ctx, rows, 500) {
+//	    if result.Error == nil {
+//	        db.InsertAll(ctx, result.Value)
+//	    }
+//	}
+func Batch[T any](ctx context.Context, in Sequence[T], n int, opts ...Option) Sequence[[]T] {
+	o := newOptions(opts)
+	n = max(n, 1)
+	r := make(Sequence[[]T])
+	spawn("Batch", o, func() {
+		defer close(r)
+		var batch []T
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			if !send(ctx, r, Success(batch)) {
+				return false
+			}
+			stageEmitted(o)
+			batch = nil
+			return true
+		}
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				if ctx.Err() == nil {
+					flush()
+				}
+				return
+			}
+			if item.Error != nil {
+				if !flush() || !send(ctx, r, Fail[[]T](item.Error)) {
+					return
+				}
+				continue
+			}
+			if batch == nil {
+				batch = make([]T, 0, n)
+			}
+			batch = append(batch, item.Value)
+			if len(batch) == n && !flush() {
+				return
+			}
+		}
+	})
+	return r
+}
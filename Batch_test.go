@@ -0,0 +1,38 @@
+package async_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	asynctest.ExpectValues(t, ctx, async.Batch(ctx, seqOf(1, 2, 3, 4, 5), 2), [][]int{{1, 2}, {3, 4}, {5}})
+}
+
+func TestBatchFlushesBeforeErrors(t *testing.T) {
+	ctx := context.Background()
+	in := make(async.Sequence[int], 4)
+	in <- async.Success(1)
+	in <- async.Fail[int](errDownstream)
+	in <- async.Success(2)
+	in <- async.Success(3)
+	close(in)
+
+	var got []any
+	for item := range async.Batch(ctx, in, 3) {
+		if item.Error != nil {
+			got = append(got, item.Error)
+		} else {
+			got = append(got, item.Value)
+		}
+	}
+	want := []any{[]int{1}, errDownstream, []int{2, 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
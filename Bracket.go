@@ -0,0 +1,100 @@
+package async
+
+import (
+	"context"
+	"errors"
+)
+
+// Bracket executes use with a resource like Do: the resource is acquired with
+// acquire, passed to use and released with release afterwards. If acquire
+// succeeds, release is invoked exactly once, whether use succeeds, fails,
+// panics or returns because the context is done. If acquire fails, use and
+// release are not invoked and the Result resolves with the error of acquire.
+//
+// An error of release is joined with the error of use, see errors.Join, so
+// that neither is lost. A panic of use is passed on after release was
+// invoked, so it is turned into a *PanicError with WithRecover.
+//
+// It supports the same options as Do, they apply to acquire, use and release
+// together.
+//
+// Example:
+//
+//	r := Bracket(ctx,
+//	    func(ctx context.Context) (*sql.Conn, error) {
+//	        return db.Conn(ctx)
+//	    },
+//	    func(ctx context.Context, conn *sql.Conn) (int64, error) {
+//	        return migrate(ctx, conn)
+//	    },
+//	    (*sql.Conn).Close,
+//	)
+func Bracket[R, T any](ctx context.Context, acquire func(ctx context.Context) (R, error), use func(ctx context.Context, r R) (T, error), release func(r R) error, opts ...Option) Result[T] {
+	return Do(ctx, func(ctx context.Context) (result T, err error) {
+		r, err := acquire(ctx)
+		if err != nil {
+			return result, err
+		}
+		defer func() {
+			if rerr := release(r); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+		}()
+		return use(ctx, r)
+	}, opts...)
+}
+
+// BracketStream is the Stream counterpart of Bracket: the resource is
+// acquired with acquire before the first step, passed to every step and
+// released with release once the stream ended, so it is held for the whole
+// stream. If acquire fails, its error is emitted as single item.
+//
+// release is invoked exactly once after acquire succeeded, when the step
+// function stopped the stream, the context is done or the stream ended
+// otherwise, but never while a step is in progress. An error of release is
+// emitted as final error item, unless the context is done.
+//
+// Like StreamState, the stream is aware of the context. It supports the same
+// options as Stream, they apply to the steps.
+//
+// Example:
+//
+//	lines := BracketStream(ctx,
+//	    func(ctx context.Context) (*os.File, error) {
+//	        return os.Open(path)
+//	    },
+//	    func(ctx context.Context, f *os.File) (Record, error, bool) {
+//	        return decoder.Next(f)
+//	    },
+//	    (*os.File).Close,
+//	)
+func BracketStream[R, T any](ctx context.Context, acquire func(ctx context.Context) (R, error), step func(ctx context.Context, r R) (T, error, bool), release func(r R) error, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("BracketStream", o, func() {
+		defer close(r)
+		res, err := acquire(ctx)
+		if err != nil {
+			send(ctx, r, Fail[T](err))
+			return
+		}
+		in := StreamState(ctx, res, func(ctx context.Context, res R) (R, T, error, bool) {
+			result, err, more := step(ctx, res)
+			return res, result, err, more
+		}, opts...)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok || !send(ctx, r, item) {
+				break
+			}
+		}
+		// the stream is aware of the context and ends once it is done,
+		// release must not race with a step in progress
+		for range in {
+		}
+		if err := release(res); err != nil {
+			send(ctx, r, Fail[T](err))
+		}
+	})
+	return r
+}
@@ -0,0 +1,257 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCircuitOpen is the error of a DoBreaker result that was rejected because
+// the circuit breaker is open.
+var ErrCircuitOpen = errors.New("async: circuit breaker is open")
+
+// errBreakerPanic is recorded as outcome for an action that panicked
+var errBreakerPanic = errors.New("async: action panicked")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets all actions pass and observes their outcomes
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all actions with ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe pass, which decides whether the
+	// breaker closes again or returns to open
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures a circuit breaker. At least one of the thresholds
+// should be set, otherwise the breaker never opens.
+type BreakerConfig struct {
+	// MaxConsecutiveFailures opens the breaker after this many failures in a
+	// row. Zero disables this threshold.
+	MaxConsecutiveFailures int
+	// FailureRate opens the breaker when the share of failures among the last
+	// Window outcomes reaches this value (0 < FailureRate <= 1). Zero disables
+	// this threshold.
+	FailureRate float64
+	// Window is the number of most recent outcomes FailureRate is computed
+	// over. The rate is only evaluated once the window is full.
+	Window int
+	// OpenTimeout is the time the breaker stays open before it lets a probe
+	// pass in the half-open state.
+	OpenTimeout time.Duration
+	// OnStateChange is invoked on every state transition, e.g. to record
+	// metrics. It is called synchronously while the breaker is locked, so it
+	// must be fast and must not call methods of the breaker.
+	OnStateChange func(from, to BreakerState)
+	// Clock is the source of time. It defaults to the clock set with SetClock.
+	Clock Clock
+}
+
+// Breaker is a circuit breaker that protects a downstream from being called
+// while it is failing. It is safe for concurrent use. Create it with
+// NewBreaker and use it with DoBreaker.
+type Breaker struct {
+	mu  sync.Mutex
+	cfg BreakerConfig
+
+	state       BreakerState
+	generation  uint64
+	openedAt    time.Time
+	probing     bool
+	consecutive int
+	outcomes    []bool
+	next        int
+	filled      int
+	failures    int
+}
+
+// NewBreaker creates a closed circuit breaker with the given configuration
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	if cfg.Clock == nil {
+		cfg.Clock = currentClock()
+	}
+	b := &Breaker{cfg: cfg}
+	if cfg.FailureRate > 0 && cfg.Window > 0 {
+		b.outcomes = make([]bool, cfg.Window)
+	}
+	return b
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	return b.state
+}
+
+// breakerTicket identifies an admitted action
+type breakerTicket struct {
+	generation uint64
+	probe      bool
+}
+
+// allow decides whether an action may pass
+func (b *Breaker) allow() (breakerTicket, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	switch b.state {
+	case BreakerOpen:
+		return breakerTicket{}, false
+	case BreakerHalfOpen:
+		if b.probing {
+			return breakerTicket{}, false
+		}
+		b.probing = true
+		return breakerTicket{generation: b.generation, probe: true}, true
+	default:
+		return breakerTicket{generation: b.generation}, true
+	}
+}
+
+// record processes the outcome of an admitted action. Outcomes of actions
+// admitted before the last state transition are ignored.
+func (b *Breaker) record(t breakerTicket, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t.generation != b.generation {
+		return
+	}
+	if t.probe {
+		b.probing = false
+		if err != nil {
+			b.transition(BreakerOpen)
+		} else {
+			b.transition(BreakerClosed)
+		}
+		return
+	}
+	failed := err != nil
+	if failed {
+		b.consecutive++
+	} else {
+		b.consecutive = 0
+	}
+	if b.outcomes != nil {
+		if b.filled == len(b.outcomes) && b.outcomes[b.next] {
+			b.failures--
+		}
+		b.outcomes[b.next] = failed
+		if failed {
+			b.failures++
+		}
+		b.next = (b.next + 1) % len(b.outcomes)
+		b.filled = min(b.filled+1, len(b.outcomes))
+	}
+	if b.cfg.MaxConsecutiveFailures > 0 && b.consecutive >= b.cfg.MaxConsecutiveFailures {
+		b.transition(BreakerOpen)
+		return
+	}
+	if b.outcomes != nil && b.filled == len(b.outcomes) && float64(b.failures)/float64(b.filled) >= b.cfg.FailureRate {
+		b.transition(BreakerOpen)
+	}
+}
+
+// release gives up the ticket of an admitted action that was not executed,
+// so that its outcome is not counted. A probe lets the next action probe.
+func (b *Breaker) release(t breakerTicket) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t.generation == b.generation && t.probe {
+		b.probing = false
+	}
+}
+
+// expire moves an open breaker to half-open once the open timeout elapsed
+func (b *Breaker) expire() {
+	if b.state == BreakerOpen && b.cfg.Clock.Now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.transition(BreakerHalfOpen)
+	}
+}
+
+// transition changes the state and resets the statistics
+func (b *Breaker) transition(to BreakerState) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.probing = false
+	b.consecutive = 0
+	b.next, b.filled, b.failures = 0, 0, 0
+	if to == BreakerOpen {
+		b.openedAt = b.cfg.Clock.Now()
+	}
+	if b.cfg.OnStateChange != nil && from != to {
+		b.cfg.OnStateChange(from, to)
+	}
+}
+
+// DoBreaker executes the given action like Do, guarded by the given circuit
+// breaker.
+//
+// While the breaker is open, the returned Result immediately resolves with
+// ErrCircuitOpen and the action is not executed. In the half-open state a
+// single action is let through as probe, all others are rejected until the
+// probe settled. Every error returned by the action, and a panic, counts as
+// failure. An action that is not executed, e.g. because an interceptor
+// short-circuited it or the context was done while it waited for a slot of
+// SetMaxConcurrency, is not counted.
+//
+// It supports the same options as Do.
+//
+// Example:
+//
+//	b := NewBreaker(BreakerConfig{MaxConsecutiveFailures: 5, OpenTimeout: 30 * time.Second})
+//	r := <-DoBreaker(ctx, b, func(ctx context.Context) (*Profile, error) {
+//	    return client.Profile(ctx, id)
+//	})
+//	if errors.Is(r.Error, ErrCircuitOpen) {
+//	    return cachedProfile(id)
+//	}
+func DoBreaker[T any](ctx context.Context, b *Breaker, action func(ctx context.Context) (T, error), opts ...Option) Result[T] {
+	ticket, ok := b.allow()
+	if !ok {
+		return resolved(Fail[T](ErrCircuitOpen))
+	}
+	// settled makes sure the ticket is handled once, by the action or,
+	// if the action was not executed, by releasing it afterwards
+	var settled atomic.Bool
+	o := newOptions(opts)
+	return doFinally(ctx, o, callSite(o), func(ctx context.Context) (T, error) {
+		if !settled.CompareAndSwap(false, true) {
+			return action(ctx)
+		}
+		returned := false
+		defer func() {
+			if !returned {
+				b.record(ticket, errBreakerPanic)
+			}
+		}()
+		value, err := action(ctx)
+		returned = true
+		b.record(ticket, err)
+		return value, err
+	}, func() {
+		if settled.CompareAndSwap(false, true) {
+			b.release(ticket)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+var errDownstream = errors.New("downstream failed")
+
+// transitions records the state changes of a breaker
+type transitions struct {
+	mu  sync.Mutex
+	got []string
+}
+
+func (tr *transitions) record(from, to async.BreakerState) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.got = append(tr.got, from.String()+"->"+to.String())
+}
+
+func (tr *transitions) expect(t *testing.T, want ...string) {
+	t.Helper()
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if !slices.Equal(tr.got, want) {
+		t.Errorf("want transitions %v, got %v", want, tr.got)
+	}
+}
+
+func succeed(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+func fail(ctx context.Context) (int, error) {
+	return 0, errDownstream
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	var tr transitions
+	b := async.NewBreaker(async.BreakerConfig{
+		MaxConsecutiveFailures: 3,
+		OpenTimeout:            time.Minute,
+		OnStateChange:          tr.record,
+		Clock:                  clock,
+	})
+
+	<-async.DoBreaker(ctx, b, fail)
+	<-async.DoBreaker(ctx, b, fail)
+	<-async.DoBreaker(ctx, b, succeed)
+	<-async.DoBreaker(ctx, b, fail)
+	<-async.DoBreaker(ctx, b, fail)
+	if got := b.State(); got != async.BreakerClosed {
+		t.Fatalf("want closed after a success reset the count, got %v", got)
+	}
+	<-async.DoBreaker(ctx, b, fail)
+	if got := b.State(); got != async.BreakerOpen {
+		t.Fatalf("want open after 3 failures in a row, got %v", got)
+	}
+
+	executed := false
+	r := <-async.DoBreaker(ctx, b, func(ctx context.Context) (int, error) {
+		executed = true
+		return 1, nil
+	})
+	if !errors.Is(r.Error, async.ErrCircuitOpen) || executed {
+		t.Fatalf("want the action rejected with ErrCircuitOpen, got %v, executed %v", r.Error, executed)
+	}
+
+	clock.Advance(time.Minute - time.Second)
+	if got := b.State(); got != async.BreakerOpen {
+		t.Fatalf("want open before the timeout elapsed, got %v", got)
+	}
+	clock.Advance(time.Second)
+	if got := b.State(); got != async.BreakerHalfOpen {
+		t.Fatalf("want half-open after the timeout, got %v", got)
+	}
+	if r := <-async.DoBreaker(ctx, b, succeed); r.Error != nil {
+		t.Fatalf("want the probe executed, got %v", r.Error)
+	}
+	if got := b.State(); got != async.BreakerClosed {
+		t.Fatalf("want closed after a successful probe, got %v", got)
+	}
+	tr.expect(t, "closed->open", "open->half-open", "half-open->closed")
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	var tr transitions
+	b := async.NewBreaker(async.BreakerConfig{
+		MaxConsecutiveFailures: 1,
+		OpenTimeout:            time.Minute,
+		OnStateChange:          tr.record,
+		Clock:                  clock,
+	})
+
+	<-async.DoBreaker(ctx, b, fail)
+	clock.Advance(time.Minute)
+	if r := <-async.DoBreaker(ctx, b, fail); !errors.Is(r.Error, errDownstream) {
+		t.Fatalf("want the probe executed, got %v", r.Error)
+	}
+	if got := b.State(); got != async.BreakerOpen {
+		t.Fatalf("want open after a failed probe, got %v", got)
+	}
+	clock.Advance(time.Minute - time.Second)
+	if got := b.State(); got != async.BreakerOpen {
+		t.Fatalf("want the open timeout restarted by the failed probe, got %v", got)
+	}
+	tr.expect(t, "closed->open", "open->half-open", "half-open->open")
+}
+
+func TestBreakerFailureRate(t *testing.T) {
+	ctx := context.Background()
+	b := async.NewBreaker(async.BreakerConfig{
+		FailureRate: 0.5,
+		Window:      4,
+		OpenTimeout: time.Minute,
+		Clock:       fakeclock.New(time.Unix(0, 0)),
+	})
+
+	for _, action := range []func(context.Context) (int, error){fail, succeed, fail} {
+		<-async.DoBreaker(ctx, b, action)
+	}
+	if got := b.State(); got != async.BreakerClosed {
+		t.Fatalf("want closed until the window is full, got %v", got)
+	}
+	<-async.DoBreaker(ctx, b, succeed)
+	if got := b.State(); got != async.BreakerOpen {
+		t.Fatalf("want open at a failure rate of 0.5, got %v", got)
+	}
+}
+
+func TestBreakerSingleProbe(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	b := async.NewBreaker(async.BreakerConfig{
+		MaxConsecutiveFailures: 1,
+		OpenTimeout:            time.Minute,
+		Clock:                  clock,
+	})
+	<-async.DoBreaker(ctx, b, fail)
+	clock.Advance(time.Minute)
+
+	release := make(chan struct{})
+	probe := async.DoBreaker(ctx, b, func(ctx context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+	var wg sync.WaitGroup
+	rejected := make(chan error, 50)
+	for range 50 {
+		wg.Go(func() {
+			rejected <- (<-async.DoBreaker(ctx, b, succeed)).Error
+		})
+	}
+	wg.Wait()
+	close(rejected)
+	for err := range rejected {
+		if !errors.Is(err, async.ErrCircuitOpen) {
+			t.Fatalf("want all actions rejected while the probe is pending, got %v", err)
+		}
+	}
+	close(release)
+	if r := <-probe; r.Error != nil {
+		t.Fatalf("want the probe to succeed, got %v", r.Error)
+	}
+	if got := b.State(); got != async.BreakerClosed {
+		t.Fatalf("want closed after the probe, got %v", got)
+	}
+}
+
+func TestBreakerShortCircuitedProbe(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	b := async.NewBreaker(async.BreakerConfig{
+		MaxConsecutiveFailures: 1,
+		OpenTimeout:            time.Minute,
+		Clock:                  clock,
+	})
+	<-async.DoBreaker(ctx, b, fail)
+	clock.Advance(time.Minute)
+
+	errDenied := errors.New("denied")
+	deny := func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) {
+			return nil, errDenied
+		}
+	}
+	if r := <-async.DoBreaker(ctx, b, succeed, async.WithInterceptors(deny)); !errors.Is(r.Error, errDenied) {
+		t.Fatalf("want the probe short-circuited, got %v", r.Error)
+	}
+	if got := b.State(); got != async.BreakerHalfOpen {
+		t.Fatalf("want half-open after a probe that was not executed, got %v", got)
+	}
+	if r := <-async.DoBreaker(ctx, b, succeed); r.Error != nil {
+		t.Fatalf("want the next action to probe, got %v", r.Error)
+	}
+	if got := b.State(); got != async.BreakerClosed {
+		t.Fatalf("want closed after the probe, got %v", got)
+	}
+}
+
+func TestBreakerProbeWithoutConcurrencySlot(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	b := async.NewBreaker(async.BreakerConfig{
+		MaxConsecutiveFailures: 1,
+		OpenTimeout:            time.Minute,
+		Clock:                  clock,
+	})
+	<-async.DoBreaker(context.Background(), b, fail)
+	clock.Advance(time.Minute)
+
+	async.SetMaxConcurrency(1)
+	defer async.SetMaxConcurrency(0)
+	release := make(chan struct{})
+	busy := async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+	for async.ConcurrencyInUse() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	probe := async.DoBreaker(ctx, b, succeed)
+	cancel()
+	if r := <-probe; !errors.Is(r.Error, context.Canceled) {
+		t.Fatalf("want the probe cancelled while waiting for a slot, got %v", r.Error)
+	}
+	close(release)
+	<-busy
+
+	if r := <-async.DoBreaker(context.Background(), b, succeed); r.Error != nil {
+		t.Fatalf("want the next action to probe, got %v", r.Error)
+	}
+	if got := b.State(); got != async.BreakerClosed {
+		t.Fatalf("want closed after the probe, got %v", got)
+	}
+}
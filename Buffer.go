@@ -0,0 +1,38 @@
+package async
+
+import (
+	"context"
+)
+
+// Buffer forwards all items of the input on a sequence with a buffer of n
+// items. It decouples a producer from a consumer with an uneven pace: the
+// producer can run up to n items ahead before it blocks.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done. Items still in the buffer at that point can be drained by the
+// consumer.
+//
+// Supported options:
+//   - WithName
+//
+// Example:
+//
+//	lines := Buffer(ctx, Stream(ctx, readLine), 1024)
+func Buffer[T any](ctx context.Context, in Sequence[T], n int, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T], max(n, 0))
+	spawn("Buffer", o, func() {
+		defer close(r)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+			stageEmitted(o)
+		}
+	})
+	return r
+}
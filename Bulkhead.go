@@ -0,0 +1,184 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrBulkheadFull is the error of a DoBulkhead result that was rejected
+// because the key is at its limit and its queue is full.
+var ErrBulkheadFull = errors.New("async: bulkhead is full")
+
+// BulkheadConfig configures a bulkhead
+type BulkheadConfig struct {
+	// Limits is the maximum number of in-flight actions per key
+	Limits map[string]int
+	// DefaultLimit is the limit of keys missing in Limits. Zero or negative
+	// means such keys are not limited.
+	DefaultLimit int
+	// MaxQueue is the number of actions per key that wait for a free slot
+	// when the key is at its limit. Zero rejects such actions immediately.
+	MaxQueue int
+}
+
+// Bulkhead caps the number of in-flight actions per named resource, so a
+// single slow downstream cannot consume all the concurrency available to the
+// others. It is safe for concurrent use. Create it with NewBulkhead and use it
+// with DoBulkhead.
+type Bulkhead struct {
+	mu    sync.Mutex
+	cfg   BulkheadConfig
+	slots map[string]*bulkheadSlots
+}
+
+type bulkheadSlots struct {
+	inFlight int
+	waiters  []chan struct{}
+}
+
+// NewBulkhead creates a bulkhead with the given configuration
+func NewBulkhead(cfg BulkheadConfig) *Bulkhead {
+	return &Bulkhead{
+		cfg:   cfg,
+		slots: map[string]*bulkheadSlots{},
+	}
+}
+
+// InFlight returns the number of actions currently executing for the key
+func (b *Bulkhead) InFlight(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.slots[key]; ok {
+		return s.inFlight
+	}
+	return 0
+}
+
+// Queued returns the number of actions currently waiting for a slot of the key
+func (b *Bulkhead) Queued(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.slots[key]; ok {
+		return len(s.waiters)
+	}
+	return 0
+}
+
+func (b *Bulkhead) limit(key string) int {
+	if l, ok := b.cfg.Limits[key]; ok {
+		return l
+	}
+	return b.cfg.DefaultLimit
+}
+
+// acquire takes a slot of the key. It returns a wait channel that is closed
+// once the slot is handed over if the action has to be queued.
+func (b *Bulkhead) acquire(key string) (chan struct{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.slots[key]
+	if !ok {
+		s = &bulkheadSlots{}
+		b.slots[key] = s
+	}
+	if l := b.limit(key); l <= 0 || s.inFlight < l {
+		s.inFlight++
+		return nil, nil
+	}
+	if len(s.waiters) >= b.cfg.MaxQueue {
+		return nil, ErrBulkheadFull
+	}
+	wait := make(chan struct{})
+	s.waiters = append(s.waiters, wait)
+	return wait, nil
+}
+
+// abandon removes a queued action. It reports false if the slot was already
+// handed over, in which case the caller owns it.
+func (b *Bulkhead) abandon(key string, wait chan struct{}) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.slots[key]
+	for i, w := range s.waiters {
+		if w == wait {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// release frees a slot of the key or hands it over to the first queued action
+func (b *Bulkhead) release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.slots[key]
+	if len(s.waiters) > 0 {
+		close(s.waiters[0])
+		s.waiters = s.waiters[1:]
+		return
+	}
+	s.inFlight--
+	if s.inFlight == 0 {
+		delete(b.slots, key)
+	}
+}
+
+// DoBulkhead executes the given action like Do, limited by the in-flight cap
+// the bulkhead defines for key.
+//
+// If the key is at its limit, the action is queued until a slot becomes free,
+// as long as the queue of the key has room. Otherwise the returned Result
+// immediately resolves with ErrBulkheadFull. A queued action is abandoned
+// when the context is done while it waits, and the Result resolves with
+// ctx.Err().
+//
+// The slot is released when the action returns, even if it panics, or when
+// the action is not executed, e.g. because an interceptor short-circuited it
+// or the context was done while it waited for a slot of SetMaxConcurrency.
+//
+// It supports the same options as Do.
+//
+// Example:
+//
+//	b := NewBulkhead(BulkheadConfig{Limits: map[string]int{"billing": 4}, MaxQueue: 16})
+//	r := <-DoBulkhead(ctx, b, "billing", func(ctx context.Context) (*Invoice, error) {
+//	    return billing.Invoice(ctx, id)
+//	})
+func DoBulkhead[T any](ctx context.Context, b *Bulkhead, key string, action func(ctx context.Context) (T, error), opts ...Option) Result[T] {
+	wait, err := b.acquire(key)
+	if err != nil {
+		return resolved(Fail[T](err))
+	}
+	// claimed makes sure the slot is given back once, by the action or, if
+	// the action was not executed, afterwards
+	var claimed atomic.Bool
+	o := newOptions(opts)
+	return doFinally(ctx, o, callSite(o), func(ctx context.Context) (T, error) {
+		if !claimed.CompareAndSwap(false, true) {
+			var zero T
+			return zero, ErrBulkheadFull
+		}
+		if wait != nil {
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				if b.abandon(key, wait) {
+					var zero T
+					return zero, ctxError(ctx)
+				}
+			}
+		}
+		defer b.release(key)
+		return action(ctx)
+	}, func() {
+		if !claimed.CompareAndSwap(false, true) {
+			return
+		}
+		if wait == nil || !b.abandon(key, wait) {
+			b.release(key)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// blocked starts an action of the bulkhead that runs until release is closed
+func blocked(ctx context.Context, b *async.Bulkhead, key string, release chan struct{}) async.Result[int] {
+	return async.DoBulkhead(ctx, b, key, func(ctx context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+}
+
+func TestBulkheadLimitsAndQueues(t *testing.T) {
+	ctx := context.Background()
+	b := async.NewBulkhead(async.BulkheadConfig{Limits: map[string]int{"billing": 1}, MaxQueue: 1})
+	release := make(chan struct{})
+
+	running := blocked(ctx, b, "billing", release)
+	queued := blocked(ctx, b, "billing", release)
+	eventually(t, "an action is queued", func() bool {
+		return b.Queued("billing") == 1
+	})
+	if r := <-blocked(ctx, b, "billing", release); !errors.Is(r.Error, async.ErrBulkheadFull) {
+		t.Fatalf("want ErrBulkheadFull with a full queue, got %v", r.Error)
+	}
+	if r := <-async.DoBulkhead(ctx, b, "search", succeed); r.Error != nil {
+		t.Fatalf("want other keys unaffected, got %v", r.Error)
+	}
+
+	close(release)
+	for _, r := range []async.Result[int]{running, queued} {
+		if item := <-r; item.Error != nil {
+			t.Fatalf("want success, got %v", item.Error)
+		}
+	}
+	if n := b.InFlight("billing"); n != 0 {
+		t.Fatalf("want no actions in flight, got %d", n)
+	}
+}
+
+func TestBulkheadAbandonedWhileQueued(t *testing.T) {
+	b := async.NewBulkhead(async.BulkheadConfig{DefaultLimit: 1, MaxQueue: 1})
+	release := make(chan struct{})
+	running := blocked(context.Background(), b, "billing", release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := blocked(ctx, b, "billing", release)
+	eventually(t, "an action is queued", func() bool {
+		return b.Queued("billing") == 1
+	})
+	cancel()
+	if r := <-queued; !errors.Is(r.Error, context.Canceled) {
+		t.Fatalf("want the queued action cancelled, got %v", r.Error)
+	}
+	if n := b.Queued("billing"); n != 0 {
+		t.Fatalf("want the cancelled action removed from the queue, got %d queued", n)
+	}
+	close(release)
+	<-running
+	if n := b.InFlight("billing"); n != 0 {
+		t.Fatalf("want no actions in flight, got %d", n)
+	}
+}
+
+func TestBulkheadActionNotExecuted(t *testing.T) {
+	ctx := context.Background()
+	b := async.NewBulkhead(async.BulkheadConfig{DefaultLimit: 1})
+	errDenied := errors.New("denied")
+	deny := func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) {
+			return nil, errDenied
+		}
+	}
+
+	if r := <-async.DoBulkhead(ctx, b, "billing", succeed, async.WithInterceptors(deny)); !errors.Is(r.Error, errDenied) {
+		t.Fatalf("want the action short-circuited, got %v", r.Error)
+	}
+	if n := b.InFlight("billing"); n != 0 {
+		t.Fatalf("want the slot released after a short-circuit, got %d in flight", n)
+	}
+	if r := <-async.DoBulkhead(ctx, b, "billing", succeed); r.Error != nil {
+		t.Fatalf("want the next action executed, got %v", r.Error)
+	}
+}
@@ -0,0 +1,49 @@
+package async
+
+import (
+	"context"
+	"fmt"
+)
+
+// CancelError is delivered in place of ctx.Err() when the context of an
+// operation was cancelled with a cause, see context.WithCancelCause. It
+// carries both, so errors.Is matches context.Canceled or
+// context.DeadlineExceeded as well as the cause.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancelCause(ctx)
+//	cancel(errShutdown)
+//	r := <-Do(ctx, func(ctx context.Context) (int, error) {
+//	    return 0, ctx.Err()
+//	})
+//	errors.Is(r.Error, context.Canceled) // true
+//	errors.Is(r.Error, errShutdown)      // true
+type CancelError struct {
+	// Err is the error of the context, context.Canceled or
+	// context.DeadlineExceeded
+	Err error
+	// Cause is the cause of the cancellation, see context.Cause
+	Cause error
+}
+
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("async: %v: %v", e.Err, e.Cause)
+}
+
+func (e *CancelError) Unwrap() []error {
+	return []error{e.Err, e.Cause}
+}
+
+// ctxError returns the error to deliver for a done context: a *CancelError if
+// the context has a cause other than its error, otherwise ctx.Err()
+func ctxError(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		return &CancelError{Err: err, Cause: cause}
+	}
+	return err
+}
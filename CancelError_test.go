@@ -0,0 +1,72 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// cancelled runs a Do that returns the error of its context once it is done
+func cancelled(ctx context.Context) error {
+	return (<-async.Do(ctx, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})).Error
+}
+
+func TestCancelErrorCause(t *testing.T) {
+	errShutdown := errors.New("shutdown")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errShutdown)
+	err := cancelled(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errShutdown) {
+		t.Fatalf("want the error and the cause of the context, got %v", err)
+	}
+	var cerr *async.CancelError
+	if !errors.As(err, &cerr) || cerr.Err != context.Canceled || cerr.Cause != errShutdown {
+		t.Fatalf("want a *CancelError with the cause, got %#v", err)
+	}
+	if want := "async: context canceled: shutdown"; err.Error() != want {
+		t.Fatalf("want %q, got %q", want, err.Error())
+	}
+}
+
+func TestCancelErrorDeadlineCause(t *testing.T) {
+	errSlow := errors.New("too slow")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Millisecond, errSlow)
+	defer cancel()
+	err := cancelled(ctx)
+	var cerr *async.CancelError
+	if !errors.As(err, &cerr) || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errSlow) {
+		t.Fatalf("want a *CancelError of the deadline with the cause, got %v", err)
+	}
+}
+
+func TestCancelErrorWithoutCause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cancelled(ctx); err != context.Canceled {
+		t.Fatalf("want ctx.Err() as it is, got %#v", err)
+	}
+
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	cancelCause(nil)
+	if err := cancelled(ctx); err != context.Canceled {
+		t.Fatalf("want ctx.Err() for a nil cause, got %#v", err)
+	}
+}
+
+func TestCancelErrorUnrelated(t *testing.T) {
+	errOther := errors.New("other")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("shutdown"))
+	r := <-async.Do(ctx, func(ctx context.Context) (int, error) {
+		return 0, errOther
+	})
+	if r.Error != errOther {
+		t.Fatalf("want the error of the action untouched, got %v", r.Error)
+	}
+}
@@ -0,0 +1,134 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of time for all time based functions of this package.
+// The default implementation uses the time package. A custom one can be set
+// for the whole process with SetClock or per invocation with WithClock, e.g.
+// to write deterministic tests with the clock of package
+// github.com/uoul/go-async/asynctest/fakeclock.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer creates a timer that fires once after the given duration
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a ticker that fires repeatedly with the given period
+	NewTicker(d time.Duration) Ticker
+	// Sleep pauses for the given duration. It returns ctx.Err() if the
+	// context is done before the duration elapsed.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// Timer is the Clock counterpart of time.Timer
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, see time.Timer.Stop
+	Stop() bool
+	// Reset changes the timer to fire after the given duration, see time.Timer.Reset
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the Clock counterpart of time.Ticker
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the ticker, see time.Ticker.Stop
+	Stop()
+}
+
+type systemClock struct{}
+
+type clockHolder struct {
+	c Clock
+}
+
+var defaultClock atomic.Pointer[clockHolder]
+
+// SetClock sets the clock for all functions of the process that have no
+// clock given with WithClock. Passing nil restores the system clock.
+func SetClock(c Clock) {
+	defer resetDefaultOptions()
+	if c == nil {
+		defaultClock.Store(nil)
+		return
+	}
+	defaultClock.Store(&clockHolder{c: c})
+}
+
+// currentClock returns the clock registered with SetClock or the system clock
+func currentClock() Clock {
+	if h := defaultClock.Load(); h != nil {
+		return h.c
+	}
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// withTimeout derives a context that is cancelled after the given duration of
+// the given clock. For the system clock this is a regular deadline. Other
+// clocks cancel the context with context.DeadlineExceeded as cause, which can
+// be retrieved with context.Cause.
+func withTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := c.(systemClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	tctx, cancel := context.WithCancelCause(ctx)
+	t := c.NewTimer(d)
+	spawn("timeout", nil, func() {
+		select {
+		case <-t.C():
+			cancel(context.DeadlineExceeded)
+		case <-tctx.Done():
+		}
+	})
+	return tctx, func() {
+		t.Stop()
+		cancel(context.Canceled)
+	}
+}
@@ -0,0 +1,33 @@
+package async
+
+import (
+	"context"
+)
+
+// Collect consumes the given sequence and returns its values in order. It
+// blocks until the sequence is closed and stops at the first error item,
+// returning the values collected so far together with that error.
+//
+// The rest of the sequence is abandoned on an error, so the producer should
+// be bound to a context that is cancelled afterwards. If the context is done
+// before the sequence is closed, its error is returned, see CancelError.
+//
+// Example:
+//
+//	users, err := Collect(ctx, Map(ctx, ids, loadUser))
+//	if err != nil {
+//	    return err
+//	}
+func Collect[T any](ctx context.Context, in Sequence[T]) ([]T, error) {
+	var values []T
+	for {
+		item, ok := receive(ctx, in)
+		if !ok {
+			return values, ctxError(ctx)
+		}
+		if item.Error != nil {
+			return values, item.Error
+		}
+		values = append(values, item.Value)
+	}
+}
@@ -0,0 +1,111 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// concurrency is the process-wide cap on the actions executing at once, see
+// SetMaxConcurrency. Without a cap, acquiring a slot costs a single atomic
+// load.
+var concurrency struct {
+	limit      atomic.Int64
+	inUse      atomic.Int64
+	hasWaiters atomic.Bool
+
+	mu sync.Mutex
+	// waiters are the acquisitions waiting for a slot, in arrival order
+	waiters []chan struct{}
+}
+
+// SetMaxConcurrency caps the number of actions of Do and steps of Stream,
+// and of all functions built on them, that execute at the same time in the
+// process. Once the cap is reached, an action waits for a free slot before it
+// starts, in roughly FIFO order, or fails with the error of its context if it
+// is done first. Zero or negative removes the cap, which is the default.
+//
+// Changing the cap takes effect for new acquisitions, actions already
+// executing keep their slot. Raising the cap lets waiting actions start
+// right away. Actions started without a cap are not counted, so they do not
+// count against a cap set while they execute.
+//
+// An action holds its slot while it executes, so an action that waits for
+// other actions, e.g. a Do awaiting a nested Do, can deadlock under a small
+// cap.
+func SetMaxConcurrency(n int) {
+	concurrency.mu.Lock()
+	defer concurrency.mu.Unlock()
+	concurrency.limit.Store(int64(max(n, 0)))
+	grantSlots()
+}
+
+// ConcurrencyInUse returns the number of actions currently executing, as
+// counted against the cap set with SetMaxConcurrency. Actions are only
+// counted while a cap is set.
+func ConcurrencyInUse() int {
+	return int(concurrency.inUse.Load())
+}
+
+// acquireSlot takes a slot of the concurrency cap, waiting for one if the cap
+// is reached. It reports whether a slot was taken, which has to be returned
+// with releaseSlot, there is none to take without a cap.
+func acquireSlot(ctx context.Context) (bool, error) {
+	limit := concurrency.limit.Load()
+	if limit <= 0 {
+		return false, nil
+	}
+	concurrency.mu.Lock()
+	if len(concurrency.waiters) == 0 && concurrency.inUse.Load() < limit {
+		concurrency.inUse.Add(1)
+		concurrency.mu.Unlock()
+		return true, nil
+	}
+	wait := make(chan struct{})
+	concurrency.waiters = append(concurrency.waiters, wait)
+	concurrency.hasWaiters.Store(true)
+	// a slot may have been released before the waiter became visible
+	grantSlots()
+	concurrency.mu.Unlock()
+	select {
+	case <-wait:
+		return true, nil
+	case <-ctx.Done():
+		concurrency.mu.Lock()
+		defer concurrency.mu.Unlock()
+		for i, w := range concurrency.waiters {
+			if w == wait {
+				concurrency.waiters = append(concurrency.waiters[:i], concurrency.waiters[i+1:]...)
+				concurrency.hasWaiters.Store(len(concurrency.waiters) > 0)
+				return false, ctxError(ctx)
+			}
+		}
+		// the slot was handed over meanwhile, pass it on
+		concurrency.inUse.Add(-1)
+		grantSlots()
+		return false, ctxError(ctx)
+	}
+}
+
+// releaseSlot returns a slot taken with acquireSlot
+func releaseSlot() {
+	concurrency.inUse.Add(-1)
+	if concurrency.hasWaiters.Load() {
+		concurrency.mu.Lock()
+		grantSlots()
+		concurrency.mu.Unlock()
+	}
+}
+
+// grantSlots hands free slots to the waiters in arrival order. The caller
+// must hold concurrency.mu.
+func grantSlots() {
+	limit := concurrency.limit.Load()
+	for len(concurrency.waiters) > 0 && (limit <= 0 || concurrency.inUse.Load() < limit) {
+		concurrency.inUse.Add(1)
+		close(concurrency.waiters[0])
+		concurrency.waiters[0] = nil
+		concurrency.waiters = concurrency.waiters[1:]
+	}
+	concurrency.hasWaiters.Store(len(concurrency.waiters) > 0)
+}
@@ -0,0 +1,183 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// withCap sets the concurrency cap for the duration of the test
+func withCap(t *testing.T, n int) {
+	t.Helper()
+	async.SetMaxConcurrency(n)
+	t.Cleanup(func() { async.SetMaxConcurrency(0) })
+}
+
+// held starts an action that holds its slot until release is closed
+func held(ctx context.Context, release <-chan struct{}) async.Result[int] {
+	return async.Do(ctx, func(ctx context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+}
+
+// waitQueued waits until n actions wait for a slot
+func waitQueued(t *testing.T, n int) {
+	t.Helper()
+	eventually(t, "actions queued for a slot", func() bool { return async.SlotWaiters() == n })
+}
+
+func TestMaxConcurrencyNeverExceeded(t *testing.T) {
+	withCap(t, 3)
+	ctx := context.Background()
+	var running, peak atomic.Int64
+	var results []async.Result[int]
+	for range 50 {
+		results = append(results, async.Do(ctx, func(ctx context.Context) (int, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			return 1, nil
+		}))
+	}
+	for _, r := range results {
+		<-r
+	}
+	if got := peak.Load(); got != 3 {
+		t.Fatalf("want at most 3 actions at once and the cap used, got a peak of %d", got)
+	}
+	if got := async.ConcurrencyInUse(); got != 0 {
+		t.Fatalf("want all slots released, %d are in use", got)
+	}
+}
+
+func TestMaxConcurrencyFIFO(t *testing.T) {
+	withCap(t, 1)
+	ctx := context.Background()
+	release := make(chan struct{})
+	busy := held(ctx, release)
+	eventually(t, "the slot taken", func() bool { return async.ConcurrencyInUse() == 1 })
+
+	var mu sync.Mutex
+	var order []int
+	var results []async.Result[int]
+	for i := range 5 {
+		results = append(results, async.Do(ctx, func(ctx context.Context) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, i)
+			return i, nil
+		}))
+		waitQueued(t, i+1)
+	}
+	close(release)
+	<-busy
+	for _, r := range results {
+		<-r
+	}
+	if !slices.Equal(order, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("want the waiters served in arrival order, got %v", order)
+	}
+}
+
+func TestMaxConcurrencyCancelledWaiter(t *testing.T) {
+	withCap(t, 1)
+	ctx := context.Background()
+	release := make(chan struct{})
+	busy := held(ctx, release)
+	eventually(t, "the slot taken", func() bool { return async.ConcurrencyInUse() == 1 })
+
+	cancelled, cancel := context.WithCancel(ctx)
+	first := async.Do(cancelled, succeed)
+	waitQueued(t, 1)
+	second := async.Do(ctx, succeed)
+	waitQueued(t, 2)
+	cancel()
+	if r := <-first; !errors.Is(r.Error, context.Canceled) {
+		t.Fatalf("want the waiter cancelled, got %v", r)
+	}
+	waitQueued(t, 1)
+
+	close(release)
+	<-busy
+	if r := <-second; r.Error != nil {
+		t.Fatalf("want the remaining waiter executed, got %v", r.Error)
+	}
+	if got := async.ConcurrencyInUse(); got != 0 {
+		t.Fatalf("want no slot leaked by the cancelled waiter, %d are in use", got)
+	}
+}
+
+func TestMaxConcurrencyChangedAtRuntime(t *testing.T) {
+	withCap(t, 1)
+	ctx := context.Background()
+	releases := []chan struct{}{make(chan struct{}), make(chan struct{}), make(chan struct{})}
+	var busy []async.Result[int]
+	busy = append(busy, held(ctx, releases[0]))
+	eventually(t, "the slot taken", func() bool { return async.ConcurrencyInUse() == 1 })
+	busy = append(busy, held(ctx, releases[1]), held(ctx, releases[2]))
+	waitQueued(t, 2)
+
+	// raising the cap starts the waiters right away
+	async.SetMaxConcurrency(3)
+	waitQueued(t, 0)
+	eventually(t, "the waiters started", func() bool { return async.ConcurrencyInUse() == 3 })
+
+	// after lowering the cap, a waiter only starts once the actions in use
+	// dropped below it
+	async.SetMaxConcurrency(2)
+	late := async.Do(ctx, succeed)
+	waitQueued(t, 1)
+	close(releases[0])
+	<-busy[0]
+	if got := async.SlotWaiters(); got != 1 {
+		t.Fatalf("want the waiter queued while the cap is reached, %d are waiting", got)
+	}
+	close(releases[1])
+	<-busy[1]
+	if r := <-late; r.Error != nil {
+		t.Fatalf("want the waiter executed after the release, got %v", r.Error)
+	}
+
+	// removing the cap starts all waiters
+	async.SetMaxConcurrency(1)
+	waiting := []async.Result[int]{async.Do(ctx, succeed), async.Do(ctx, succeed)}
+	waitQueued(t, 2)
+	async.SetMaxConcurrency(0)
+	for _, r := range waiting {
+		if item := <-r; item.Error != nil {
+			t.Fatalf("want the waiters executed without a cap, got %v", item.Error)
+		}
+	}
+	close(releases[2])
+	<-busy[2]
+	if got := async.ConcurrencyInUse(); got != 0 {
+		t.Fatalf("want all slots released, %d are in use", got)
+	}
+}
+
+func TestMaxConcurrencyUncounted(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	defer func() {
+		close(release)
+		<-r
+	}()
+	<-started
+	if got := async.ConcurrencyInUse(); got != 0 {
+		t.Fatalf("want actions not counted without a cap, %d are in use", got)
+	}
+}
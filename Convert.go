@@ -0,0 +1,55 @@
+package async
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEmptySequence is the error of FirstAsResult for a sequence that is
+// closed without an item.
+var ErrEmptySequence = errors.New("async: sequence closed without items")
+
+// AsSequence returns the given Result as Sequence with a single item, so that
+// the stages working on sequences can be applied to the outcome of Do. As
+// both are channels of the same type, no goroutine is involved: the sequence
+// delivers the outcome and is closed when the Result is closed.
+//
+// Example:
+//
+//	logged := Tap(ctx, AsSequence(Do(ctx, fetchUser)), func(u User) {
+//	    log.Printf("fetched %s", u.Name)
+//	})
+func AsSequence[T any](r Result[T]) Sequence[T] {
+	return Sequence[T](r)
+}
+
+// FirstAsResult resolves with the first item of the sequence, so that the
+// first element of a stream can be awaited like the outcome of Do. The rest
+// of the sequence is not consumed, so its producer should be bound to a
+// context that is cancelled afterwards.
+//
+// If the sequence is closed without an item, the Result fails with
+// ErrEmptySequence. If the context is done before, it fails with the error of
+// the context, see CancelError.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	r := <-FirstAsResult(ctx, Stream(ctx, pollReady))
+func FirstAsResult[T any](ctx context.Context, s Sequence[T]) Result[T] {
+	r := make(Result[T], 1)
+	spawn("FirstAsResult", nil, func() {
+		defer close(r)
+		item, ok := receive(ctx, s)
+		switch {
+		case ok:
+			r <- item
+		case ctx.Err() != nil:
+			r <- Fail[T](ctxError(ctx))
+		default:
+			r <- Fail[T](ErrEmptySequence)
+		}
+	})
+	return r
+}
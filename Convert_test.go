@@ -0,0 +1,47 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+func TestAsSequence(t *testing.T) {
+	ctx := context.Background()
+	asynctest.ExpectValues(t, ctx, async.AsSequence(async.Do(ctx, succeed)), []int{1})
+
+	values, errs := items(async.AsSequence(async.Do(ctx, fail)))
+	if len(values) != 0 || len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the error as single item, got %v and %v", values, errs)
+	}
+}
+
+func TestAsSequenceEmpty(t *testing.T) {
+	r := make(async.Result[int])
+	close(r)
+	asynctest.ExpectValues(t, context.Background(), async.AsSequence(r), nil)
+}
+
+func TestFirstAsResult(t *testing.T) {
+	ctx := context.Background()
+	in := seqOf(1, 2, 3)
+	asynctest.ExpectError(t, ctx, async.FirstAsResult(ctx, in), nil)
+	if len(in) != 2 {
+		t.Fatalf("want the rest of the sequence not consumed, %d items are left", len(in))
+	}
+}
+
+func TestFirstAsResultEmpty(t *testing.T) {
+	ctx := context.Background()
+	asynctest.ExpectError(t, ctx, async.FirstAsResult(ctx, seqOf[int]()), async.ErrEmptySequence)
+}
+
+func TestFirstAsResultCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := async.FirstAsResult(ctx, make(async.Sequence[int]))
+	cancel()
+	asynctest.ExpectError(t, context.Background(), r, context.Canceled)
+}
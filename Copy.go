@@ -0,0 +1,127 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// defaultProgressBytes is the progress step of Copy without WithProgress
+const defaultProgressBytes = 1 << 20
+
+// CopyError is the error of a Copy that failed or was cancelled. It records
+// the number of bytes written until then.
+type CopyError struct {
+	Written int64
+	Err     error
+}
+
+func (e *CopyError) Error() string {
+	return fmt.Sprintf("async: copy failed after %d bytes: %v", e.Written, e.Err)
+}
+
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+// Copy copies from src to dst in a goroutine, like io.Copy. The Result
+// resolves with the total number of bytes copied, or with a *CopyError that
+// carries the error and the bytes copied so far. The Sequence reports the
+// cumulative number of bytes copied while the copy is in progress, which is
+// meant for progress bars.
+//
+// The progress is reported every time another 1 MiB was copied, or as
+// configured with WithProgress, and once more at the end. It is conflated:
+// if the consumer does not keep up, it only sees the newest count, the copy
+// is never slowed down by it. The progress sequence is closed when the copy
+// ends and may be ignored.
+//
+// The context is checked between the chunks, so cancellation stops the copy
+// at the next chunk boundary and the Result fails with a *CopyError wrapping
+// ctx.Err().
+//
+// Supported options:
+//   - WithClock
+//   - WithProgress
+//
+// Example:
+//
+//	total, progress := Copy(ctx, file, resp.Body, WithProgress(0, 100*time.Millisecond))
+//	for p := range progress {
+//	    bar.Set(p.Value)
+//	}
+//	r := <-total
+func Copy(ctx context.Context, dst io.Writer, src io.Reader, opts ...Option) (Result[int64], Sequence[int64]) {
+	o := newOptions(opts)
+	r := make(Result[int64], 1)
+	progress := make(Sequence[int64], 1)
+	every, interval := o.progressBytes, o.progressInterval
+	if every <= 0 && interval <= 0 {
+		every = defaultProgressBytes
+	}
+	spawn("Copy", o, func() {
+		defer close(r)
+		defer close(progress)
+		var written, reported int64
+		lastReport := o.clock.Now()
+		report := func() {
+			reported, lastReport = written, o.clock.Now()
+			conflate(progress, Success(written))
+		}
+		buf := make([]byte, 32*1024)
+		for {
+			if err := ctxError(ctx); err != nil {
+				report()
+				r <- Fail[int64](&CopyError{Written: written, Err: err})
+				return
+			}
+			n, rerr := src.Read(buf)
+			if n > 0 {
+				w, werr := dst.Write(buf[:n])
+				written += int64(w)
+				if werr == nil && w < n {
+					werr = io.ErrShortWrite
+				}
+				if werr != nil {
+					report()
+					r <- Fail[int64](&CopyError{Written: written, Err: werr})
+					return
+				}
+			}
+			if errors.Is(rerr, io.EOF) {
+				report()
+				r <- Success(written)
+				return
+			}
+			if rerr != nil {
+				report()
+				r <- Fail[int64](&CopyError{Written: written, Err: rerr})
+				return
+			}
+			if every > 0 && written-reported >= every || interval > 0 && o.clock.Now().Sub(lastReport) >= interval {
+				report()
+			}
+		}
+	})
+	return r, progress
+}
+
+// conflate sends the item on a sequence with a buffer of one without
+// blocking, replacing an item the consumer did not receive yet. It must only
+// be used by the single producer of the sequence.
+func conflate[T any](s Sequence[T], item _Result[T]) {
+	select {
+	case s <- item:
+		return
+	default:
+	}
+	select {
+	case <-s:
+	default:
+	}
+	select {
+	case s <- item:
+	default:
+	}
+}
@@ -0,0 +1,77 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Deadline forwards the items of the input until d elapsed since the stage
+// was started. Then a final error item wrapping context.DeadlineExceeded is
+// emitted and the returned sequence is closed, so consumers can tell that the
+// sequence was cut short rather than completed. An item the consumer has not
+// received when the deadline fires is dropped. The returned sequence has a
+// buffer of one item, which takes the final item at the deadline, so the
+// final item reaches a slow consumer and the stage never outlives the
+// deadline even if nobody reads anymore.
+//
+// Stream can be limited the same way with WithMaxDuration.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	for result := range Deadline(ctx, events, time.Minute) {
+//	    if errors.Is(result.Error, context.DeadlineExceeded) {
+//	        log.Print("stopped listening after one minute")
+//	    }
+//	}
+func Deadline[T any](ctx context.Context, in Sequence[T], d time.Duration, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	// the slot is taken by the final item, see expire
+	r := make(Sequence[T], 1)
+	spawn("Deadline", o, func() {
+		defer close(r)
+		t := o.clock.NewTimer(d)
+		defer t.Stop()
+		for {
+			var item _Result[T]
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C():
+				expire(r, d)
+				return
+			case i, ok := <-in:
+				if !ok {
+					return
+				}
+				item = i
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C():
+				expire(r, d)
+				return
+			case r <- item:
+			}
+		}
+	})
+	return r
+}
+
+// expire puts the final error item of a sequence that exceeded its maximum
+// duration d into the single buffer slot of r, without waiting for the
+// consumer. An item still in the slot was not received before the deadline
+// and is dropped. The caller must be the only sender on r.
+func expire[T any](r Sequence[T], d time.Duration) {
+	select {
+	case <-r:
+	default:
+	}
+	r <- Fail[T](maxDurationError(d))
+}
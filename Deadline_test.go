@@ -0,0 +1,99 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+func TestWithMaxDurationProducerExitsWithoutConsumer(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	clock := fakeclock.New(time.Unix(0, 0))
+	reasons, onClose := closeReason()
+	n := 0
+	seq := async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		return n, nil, true
+	}, async.WithMaxDuration(time.Minute), async.WithClock(clock), async.WithOnClose(onClose))
+	if item := <-seq; item.Value != 1 {
+		t.Fatalf("want the first item, got %v", item)
+	}
+
+	// the producer is blocked sending the second item when the deadline fires
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	expectReason(t, reasons, async.CloseLimitExceeded)
+}
+
+func TestDeadlineExitsWithoutConsumer(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int], 1)
+	in <- async.Success(1)
+	async.Deadline(context.Background(), in, time.Minute, async.WithClock(clock))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+}
+
+func TestDeadlineForwardsUntilExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	asynctest.ExpectValues(t, ctx, async.Deadline(ctx, seqOf(1, 2, 3), time.Minute, async.WithClock(clock)), []int{1, 2, 3})
+}
+
+func TestDeadlineSlowConsumer(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	seq := async.Deadline(ctx, in, time.Minute, async.WithClock(clock))
+	clock.BlockUntil(1)
+	in <- async.Success(1)
+	if item := <-seq; item.Value != 1 {
+		t.Fatalf("want the first item, got %v", item)
+	}
+
+	// the consumer is still busy with the first item when the deadline fires
+	in <- async.Success(2)
+	in <- async.Success(3)
+	clock.Advance(time.Minute)
+	var last error
+	for item := range seq {
+		last = item.Error
+	}
+	if !errors.Is(last, context.DeadlineExceeded) {
+		t.Fatalf("want the sequence to end with the deadline error, got %v", last)
+	}
+}
+
+func TestWithMaxDurationSlowConsumer(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	n := 0
+	seq := async.Stream(ctx, func(ctx context.Context) (int, error, bool) {
+		n++
+		return n, nil, true
+	}, async.WithMaxDuration(time.Minute), async.WithClock(clock))
+	if item := <-seq; item.Value != 1 {
+		t.Fatalf("want the first item, got %v", item)
+	}
+
+	// the producer is blocked sending while the consumer is still busy
+	clock.BlockUntil(1)
+	eventually(t, "the producer blocked on the full buffer", func() bool { return len(seq) == 1 })
+	clock.Advance(time.Minute)
+	var last error
+	for item := range seq {
+		last = item.Error
+	}
+	if !errors.Is(last, context.DeadlineExceeded) {
+		t.Fatalf("want the stream to end with the deadline error, got %v", last)
+	}
+}
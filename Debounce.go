@@ -0,0 +1,77 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Debounce emits a successful item of the input only after the input has been
+// silent for the quiet duration. Every new item replaces the pending one and
+// restarts the quiet period, so only the latest item of a burst is emitted
+// (trailing edge debouncing).
+//
+// Error items are not debounced, they are forwarded immediately and do not
+// affect the pending item.
+//
+// When the input is closed, a pending item is flushed before the returned
+// sequence is closed. When the context is done, the pending item is dropped
+// and the returned sequence is closed.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	for result := range Debounce(ctx, keystrokes, 300*time.Millisecond) {
+//	    search(result.Value)
+//	}
+func Debounce[T any](ctx context.Context, in Sequence[T], quiet time.Duration, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Debounce", o, func() {
+		defer close(r)
+		var (
+			timer   Timer
+			fire    <-chan time.Time
+			pending _Result[T]
+		)
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-fire:
+				fire = nil
+				if !send(ctx, r, pending) {
+					return
+				}
+			case item, ok := <-in:
+				if !ok {
+					if fire != nil {
+						send(ctx, r, pending)
+					}
+					return
+				}
+				if item.Error != nil {
+					if !send(ctx, r, item) {
+						return
+					}
+					continue
+				}
+				pending = item
+				if timer == nil {
+					timer = o.clock.NewTimer(quiet)
+				} else {
+					timer.Stop()
+					timer.Reset(quiet)
+				}
+				fire = timer.C()
+			}
+		}
+	})
+	return r
+}
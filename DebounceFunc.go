@@ -0,0 +1,132 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// debouncer is the state shared by the calls of a function returned by
+// DebounceFunc
+type debouncer[T any] struct {
+	quiet  time.Duration
+	action func(ctx context.Context) (T, error)
+	opts   []Option
+	o      *options
+
+	mu    sync.Mutex
+	group *debounceGroup[T]
+}
+
+// debounceGroup is a burst of calls that settle with the same execution
+type debounceGroup[T any] struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timer   Timer
+	waiters map[*waiter[T]]struct{}
+}
+
+// DebounceFunc wraps the action so that a burst of calls executes it only
+// once: every call restarts the quiet period, and the action is executed
+// when no call arrived for the quiet duration. The Results of all calls of
+// the burst resolve with the outcome of that single execution. Calls that
+// arrive while the action executes start the next burst.
+//
+// A call whose context is done detaches from the burst, its Result resolves
+// with the error of the context. The execution is only cancelled, or not
+// started at all, if every call of the burst detached. The action is executed
+// with a context that carries the values of the context of the first call of
+// the burst.
+//
+// The returned function is safe for concurrent use. It supports the same
+// options as Do, which executes the action, plus WithClock for the quiet
+// period.
+//
+// Example:
+//
+//	save := DebounceFunc(time.Second, func(ctx context.Context) (Revision, error) {
+//	    return store.Save(ctx, doc.Snapshot())
+//	})
+//	editor.OnChange(func() {
+//	    go func() {
+//	        if r := <-save(ctx); r.Error != nil {
+//	            log.Printf("saving failed: %v", r.Error)
+//	        }
+//	    }()
+//	})
+func DebounceFunc[T any](quiet time.Duration, action func(ctx context.Context) (T, error), opts ...Option) func(ctx context.Context) Result[T] {
+	d := &debouncer[T]{quiet: quiet, action: action, opts: opts, o: newOptions(opts)}
+	return d.call
+}
+
+func (d *debouncer[T]) call(ctx context.Context) Result[T] {
+	if ctx.Err() != nil {
+		return resolved(Fail[T](ctxError(ctx)))
+	}
+	w := &waiter[T]{r: make(Result[T], 1)}
+	d.mu.Lock()
+	g, first := d.group, d.group == nil
+	if first {
+		gctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		g = &debounceGroup[T]{
+			ctx:     gctx,
+			cancel:  cancel,
+			timer:   d.o.clock.NewTimer(d.quiet),
+			waiters: map[*waiter[T]]struct{}{},
+		}
+		d.group = g
+	} else {
+		g.timer.Reset(d.quiet)
+	}
+	g.waiters[w] = struct{}{}
+	w.stop = context.AfterFunc(ctx, func() {
+		d.detach(g, w)
+		w.resolve(Fail[T](ctxError(ctx)))
+	})
+	d.mu.Unlock()
+	if first {
+		spawn("DebounceFunc", d.o, func() {
+			d.run(g)
+		})
+	}
+	return w.r
+}
+
+// detach removes a call from its group and cancels the group once it has no
+// calls left
+func (d *debouncer[T]) detach(g *debounceGroup[T], w *waiter[T]) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(g.waiters, w)
+	if len(g.waiters) == 0 {
+		g.cancel()
+		if d.group == g {
+			d.group = nil
+		}
+	}
+}
+
+// run executes the action of the group once its quiet period elapsed
+func (d *debouncer[T]) run(g *debounceGroup[T]) {
+	defer g.cancel()
+	select {
+	case <-g.timer.C():
+	case <-g.ctx.Done():
+		g.timer.Stop()
+		return
+	}
+	d.mu.Lock()
+	if d.group == g {
+		d.group = nil
+	}
+	d.mu.Unlock()
+	item := <-Do(g.ctx, d.action, d.opts...)
+	d.mu.Lock()
+	waiters := g.waiters
+	g.waiters = nil
+	d.mu.Unlock()
+	for w := range waiters {
+		w.resolve(item)
+		w.stop()
+	}
+}
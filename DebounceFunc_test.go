@@ -0,0 +1,97 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// executions returns an action counting its executions in n
+func executions(n *atomic.Int64) func(ctx context.Context) (int64, error) {
+	return func(ctx context.Context) (int64, error) {
+		return n.Add(1), nil
+	}
+}
+
+// pending reports whether the result is still unresolved after a short time
+func pending[T any](r async.Result[T]) bool {
+	select {
+	case <-r:
+		return false
+	case <-time.After(10 * time.Millisecond):
+		return true
+	}
+}
+
+func TestDebounceFuncTrailing(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	var n atomic.Int64
+	save := async.DebounceFunc(time.Second, executions(&n), async.WithClock(clock))
+
+	// every call restarts the quiet period
+	results := []async.Result[int64]{save(ctx)}
+	for range 3 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second - time.Millisecond)
+		results = append(results, save(ctx))
+	}
+	clock.Advance(time.Second - time.Millisecond)
+	if !pending(results[0]) || n.Load() != 0 {
+		t.Fatalf("want no execution before the quiet period elapsed, got %d", n.Load())
+	}
+	clock.Advance(time.Millisecond)
+	for _, r := range results {
+		if item := <-r; item.Value != 1 || item.Error != nil {
+			t.Fatalf("want every call of the burst to share the single execution, got %v", item)
+		}
+	}
+
+	// the next call starts the next burst
+	next := save(ctx)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if item := <-next; item.Value != 2 {
+		t.Fatalf("want a new execution for the next burst, got %v", item)
+	}
+}
+
+func TestDebounceFuncDetachedCalls(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	var n atomic.Int64
+	save := async.DebounceFunc(time.Second, executions(&n), async.WithClock(clock))
+
+	// a call that detached does not cancel the execution for the others
+	cancelled, cancel := context.WithCancel(ctx)
+	gone, kept := save(cancelled), save(ctx)
+	cancel()
+	if item := <-gone; !errors.Is(item.Error, context.Canceled) {
+		t.Fatalf("want the detached call to fail, got %v", item)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if item := <-kept; item.Value != 1 {
+		t.Fatalf("want the action executed for the remaining call, got %v", item)
+	}
+
+	// once every call detached, the pending execution is dropped
+	cancelled, cancel = context.WithCancel(ctx)
+	r := save(cancelled)
+	clock.BlockUntil(1)
+	cancel()
+	<-r
+	eventually(t, "the pending execution dropped", func() bool { return clock.Waiters() == 0 })
+	clock.Advance(time.Second)
+	if got := n.Load(); got != 1 {
+		t.Fatalf("want no execution without calls left, got %d executions", got)
+	}
+}
@@ -0,0 +1,80 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// debounced sends the values to in and then an error item, which Debounce
+// forwards right away. Receiving it guarantees that the values were
+// processed and the quiet period restarted.
+func debounced(t *testing.T, in, out async.Sequence[int], values ...int) {
+	t.Helper()
+	for _, v := range values {
+		in <- async.Success(v)
+	}
+	in <- async.Fail[int](errDownstream)
+	if item := <-out; !errors.Is(item.Error, errDownstream) {
+		t.Fatalf("want the error item forwarded first, got %v", item)
+	}
+}
+
+func TestDebounceEmitsAfterQuietPeriod(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	out := async.Debounce(ctx, in, time.Second, async.WithClock(clock))
+
+	debounced(t, in, out, 1)
+	clock.Advance(500 * time.Millisecond)
+	debounced(t, in, out, 2)
+	// the first quiet period would end now, but the second item restarted it
+	clock.Advance(500 * time.Millisecond)
+	debounced(t, in, out)
+	clock.Advance(500 * time.Millisecond)
+	if item := <-out; item.Value != 2 || item.Error != nil {
+		t.Fatalf("want the latest item after the quiet period, got %v", item)
+	}
+
+	close(in)
+	asynctest.ExpectValues(t, ctx, out, nil)
+}
+
+func TestDebounceFlushesOnClose(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	out := async.Debounce(ctx, in, time.Second, async.WithClock(clock))
+
+	debounced(t, in, out, 1, 2)
+	close(in)
+	asynctest.ExpectValues(t, ctx, out, []int{2})
+}
+
+func TestDebounceBurst(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	out := async.Debounce(ctx, in, time.Second, async.WithClock(clock))
+
+	burst := make([]int, 100)
+	for i := range burst {
+		burst[i] = i + 1
+	}
+	debounced(t, in, out, burst...)
+	if n := clock.Waiters(); n != 1 {
+		t.Fatalf("want a single pending timer after the burst, got %d", n)
+	}
+	clock.Advance(time.Second)
+	if item := <-out; item.Value != 100 || item.Error != nil {
+		t.Fatalf("want the last item of the burst, got %v", item)
+	}
+	close(in)
+	asynctest.ExpectValues(t, ctx, out, nil)
+}
@@ -0,0 +1,100 @@
+package async
+
+import (
+	"context"
+)
+
+type _Result2[A, B any] struct {
+	First  A
+	Second B
+	Error  error
+}
+
+// Result2 is the channel returned by Do2, it receives the two values of the
+// action together with its error.
+type Result2[A, B any] chan _Result2[A, B]
+
+// Do2 executes the given action asynchronously in a goroutine, like Do, for
+// actions that return two values plus an error, e.g. a payload and its
+// metadata. It saves the definition of a struct only to carry the values.
+//
+// The returned channel receives exactly one value and is closed afterwards.
+// If the action returns an error, First and Second hold the zero values.
+//
+// Supported options:
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRecover
+//   - WithTracer
+//   - WithTracker
+//
+// Example:
+//
+//	result := Do2(ctx, func(ctx context.Context) ([]byte, http.Header, error) {
+//	    return download(ctx, url)
+//	})
+//	body, header, err := Await2(ctx, result)
+//	if err != nil {
+//	    log.Printf("error: %v", err)
+//	}
+func Do2[A, B any](ctx context.Context, action func(ctx context.Context) (A, B, error), opts ...Option) Result2[A, B] {
+	o := newOptions(opts)
+	r := make(Result2[A, B], 1)
+	doDeliver(ctx, o, callSite(o), "Do2", func(ctx context.Context) (Pair[A, B], error) {
+		first, second, err := action(ctx)
+		return Pair[A, B]{First: first, Second: second}, err
+	}, func(p Pair[A, B], err error) {
+		r <- _Result2[A, B]{First: p.First, Second: p.Second, Error: err}
+		close(r)
+	})
+	return r
+}
+
+// Await2 waits for the result of Do2 and returns its values and error. If the
+// context is done before, the zero values and ctx.Err() are returned. A
+// channel that was already drained yields the zero values and nil.
+func Await2[A, B any](ctx context.Context, r Result2[A, B]) (A, B, error) {
+	select {
+	case item := <-r:
+		return item.First, item.Second, item.Error
+	case <-ctx.Done():
+		var first A
+		var second B
+		return first, second, ctxError(ctx)
+	}
+}
+
+// Paired converts the result of Do2 into a Result of a Pair, so that it can
+// be used with the functions of this package working on Result and Sequence.
+// The returned Result is closed without a value if the context is done before
+// the result arrived.
+//
+// Example:
+//
+//	for item := range Paired(ctx, Do2(ctx, download)) {
+//	    log.Printf("%d bytes, %v", len(item.Value.First), item.Value.Second)
+//	}
+func Paired[A, B any](ctx context.Context, r Result2[A, B]) Result[Pair[A, B]] {
+	out := make(Result[Pair[A, B]], 1)
+	spawn("Paired", nil, func() {
+		defer close(out)
+		select {
+		case item, ok := <-r:
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				out <- Fail[Pair[A, B]](item.Error)
+			} else {
+				out <- Success(Pair[A, B]{First: item.First, Second: item.Second})
+			}
+		case <-ctx.Done():
+		}
+	})
+	return out
+}
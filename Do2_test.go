@@ -0,0 +1,34 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestDo2(t *testing.T) {
+	ctx := context.Background()
+	first, second, err := async.Await2(ctx, async.Do2(ctx, func(ctx context.Context) (string, int, error) {
+		return "body", 4, nil
+	}))
+	if first != "body" || second != 4 || err != nil {
+		t.Fatalf("want both values, got %q, %d, %v", first, second, err)
+	}
+}
+
+func TestDo2Error(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	r := async.Do2(ctx, func(ctx context.Context) (string, int, error) {
+		return "partial", 1, errFailed
+	})
+	first, second, err := async.Await2(ctx, r)
+	if first != "" || second != 0 || err != errFailed {
+		t.Fatalf("want the zero values and the error, got %q, %d, %v", first, second, err)
+	}
+	if _, ok := <-r; ok {
+		t.Fatal("want the channel closed after the result")
+	}
+}
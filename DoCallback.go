@@ -0,0 +1,64 @@
+package async
+
+import (
+	"context"
+)
+
+// DoCallback executes the given action asynchronously in a goroutine, like
+// Do, but hands the outcome to done instead of a channel, for integration
+// points such as event loops or cgo callbacks that cannot block on a channel.
+//
+// done is invoked exactly once, on the goroutine of the action after it
+// returned. A panic of the action is always recovered and passed to done as
+// *PanicError, as if WithRecover was set. If the context is already done when
+// the goroutine starts, the action is not executed and done receives the
+// error of the context.
+//
+// Supported options:
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithTracer
+//   - WithTracker
+//
+// Example:
+//
+//	DoCallback(ctx, func(ctx context.Context) (*Thumbnail, error) {
+//	    return render(ctx, path)
+//	}, func(t *Thumbnail, err error) {
+//	    ui.Post(func() { view.Show(t, err) })
+//	})
+func DoCallback[T any](ctx context.Context, action func(ctx context.Context) (T, error), done func(v T, err error), opts ...Option) {
+	o := newOptions(append(opts[:len(opts):len(opts)], WithRecover()))
+	doDeliver(ctx, o, callSite(o), "DoCallback", func(ctx context.Context) (T, error) {
+		if err := ctxError(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		return action(ctx)
+	}, done)
+}
+
+// DoCallbacks is DoCallback with separate callbacks: onSuccess receives the
+// value of an action that succeeded, onError the error of one that failed.
+// Exactly one of them is invoked. Nil callbacks are skipped.
+//
+// It supports the same options as DoCallback.
+//
+// Example:
+//
+//	DoCallbacks(ctx, fetchProfile, view.ShowProfile, view.ShowError)
+func DoCallbacks[T any](ctx context.Context, action func(ctx context.Context) (T, error), onSuccess func(v T), onError func(err error), opts ...Option) {
+	DoCallback(ctx, action, func(v T, err error) {
+		switch {
+		case err != nil && onError != nil:
+			onError(err)
+		case err == nil && onSuccess != nil:
+			onSuccess(v)
+		}
+	}, opts...)
+}
@@ -0,0 +1,58 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// outcome is the outcome handed to a callback of DoCallback
+type outcome struct {
+	v   int
+	err error
+}
+
+// callback returns a callback of DoCallback and the channel it reports to
+func callback() (func(v int, err error), chan outcome) {
+	ch := make(chan outcome, 1)
+	return func(v int, err error) {
+		ch <- outcome{v, err}
+	}, ch
+}
+
+func TestDoCallback(t *testing.T) {
+	done, ch := callback()
+	async.DoCallback(context.Background(), func(ctx context.Context) (int, error) {
+		return 1, nil
+	}, done)
+	if got := <-ch; got != (outcome{v: 1}) {
+		t.Fatalf("want the value, got %+v", got)
+	}
+}
+
+func TestDoCallbackPanic(t *testing.T) {
+	done, ch := callback()
+	async.DoCallback(context.Background(), func(ctx context.Context) (int, error) {
+		panic("boom")
+	}, done)
+	var perr *async.PanicError
+	if got := <-ch; got.v != 0 || !errors.As(got.err, &perr) {
+		t.Fatalf("want the panic recovered, got %+v", got)
+	}
+}
+
+func TestDoCallbackCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	done, ch := callback()
+	async.DoCallback(ctx, func(ctx context.Context) (int, error) {
+		called = true
+		return 1, nil
+	}, done)
+	if got := <-ch; called || got.err != context.Canceled {
+		t.Fatalf("want the action skipped with the error of the context, got %+v", got)
+	}
+}
@@ -0,0 +1,63 @@
+package async
+
+import (
+	"context"
+)
+
+// DoVoid executes the given action asynchronously in a goroutine, like Do,
+// for actions that produce no value but an error.
+//
+// The returned channel receives exactly one value, the error of the action or
+// nil, and is closed afterwards. This avoids a dummy type parameter such as
+// func(ctx context.Context) (struct{}, error) for actions run for their side
+// effects, while keeping the instrumentation of Do.
+//
+// Supported options:
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRecover
+//   - WithTracer
+//   - WithTracker
+//
+// Example:
+//
+//	done := DoVoid(ctx, func(ctx context.Context) error {
+//	    return cache.Invalidate(ctx, key)
+//	}, WithName("invalidate"))
+//	if err := AwaitVoid(ctx, done); err != nil {
+//	    log.Printf("error: %v", err)
+//	}
+func DoVoid(ctx context.Context, action func(ctx context.Context) error, opts ...Option) <-chan error {
+	o := newOptions(opts)
+	r := make(chan error, 1)
+	doDeliver(ctx, o, callSite(o), "DoVoid", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, action(ctx)
+	}, func(_ struct{}, err error) {
+		r <- err
+		close(r)
+	})
+	return r
+}
+
+// AwaitVoid waits for the error delivered by DoVoid and returns it. If the
+// context is done before, ctx.Err() is returned. A channel that was already
+// drained yields nil.
+//
+// Example:
+//
+//	if err := AwaitVoid(ctx, DoVoid(ctx, flush)); err != nil {
+//	    return err
+//	}
+func AwaitVoid(ctx context.Context, ch <-chan error) error {
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		return ctxError(ctx)
+	}
+}
@@ -0,0 +1,35 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestDoVoid(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	for _, want := range []error{nil, errFailed} {
+		done := async.DoVoid(ctx, func(ctx context.Context) error {
+			return want
+		})
+		if err := async.AwaitVoid(ctx, done); err != want {
+			t.Fatalf("want %v, got %v", want, err)
+		}
+		if _, ok := <-done; ok {
+			t.Fatal("want the channel closed after the error")
+		}
+	}
+}
+
+func TestDoVoidNamed(t *testing.T) {
+	errFailed := errors.New("failed")
+	err := async.AwaitVoid(context.Background(), async.DoVoid(context.Background(), func(ctx context.Context) error {
+		return errFailed
+	}, async.WithName("flush")))
+	if aerr, ok := async.AsAsyncError(err); !ok || aerr.TaskName != "flush" || aerr.Err != errFailed {
+		t.Fatalf("want the error of the named task wrapped, got %#v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package async
+
+import (
+	"context"
+)
+
+// Indexed is a value together with its position in a Sequence.
+type Indexed[T any] struct {
+	Index int
+	Value T
+}
+
+// Enumerate attaches a zero based index to every item of the input sequence.
+//
+// By default only successful items are counted. Use WithCountErrors to count
+// every item, including the error items.
+//
+// Error items are forwarded with the index at which they occurred, both in
+// the Index field of the value and wrapped into an *IndexedError. Without
+// WithCountErrors this is the index the next successful item will get.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithCountErrors
+//
+// Example:
+//
+//	for result := range Enumerate(ctx, records) {
+//	    if result.Error != nil {
+//	        log.Printf("error: %v", result.Error) // e.g. "async: item 5234: ..."
+//	        continue
+//	    }
+//	    if err := validate(result.Value.Value); err != nil {
+//	        log.Printf("item %d failed validation: %v", result.Value.Index, err)
+//	    }
+//	}
+func Enumerate[T any](ctx context.Context, in Sequence[T], opts ...Option) Sequence[Indexed[T]] {
+	o := newOptions(opts)
+	r := make(Sequence[Indexed[T]])
+	spawn("Enumerate", o, func() {
+		defer close(r)
+		index := 0
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			out := _Result[Indexed[T]]{Value: Indexed[T]{Index: index, Value: item.Value}}
+			if item.Error != nil {
+				out.Error = indexInputError(index, item.Error)
+			}
+			if item.Error == nil || o.countErrors {
+				index++
+			}
+			if !send(ctx, r, out) {
+				return
+			}
+		}
+	})
+	return r
+}
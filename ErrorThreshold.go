@@ -0,0 +1,74 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyErrors is wrapped by the final error item of a sequence that was
+// aborted because it reached its maximum number of error items.
+var ErrTooManyErrors = errors.New("async: too many errors")
+
+// ErrorThreshold forwards every item of the input until maxErrors error items
+// have been seen. Instead of the error item reaching the threshold, a final
+// error item wrapping ErrTooManyErrors together with all the collected errors
+// is emitted and the returned sequence is closed. This protects a pipeline
+// from processing the rest of an upstream that has clearly gone bad.
+//
+// A maxErrors of zero or less disables the threshold. Stream can be limited
+// the same way with WithMaxErrors.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Example:
+//
+//	for result := range ErrorThreshold(ctx, records, 100) {
+//	    if errors.Is(result.Error, ErrTooManyErrors) {
+//	        return result.Error
+//	    }
+//	    ...
+//	}
+func ErrorThreshold[T any](ctx context.Context, in Sequence[T], maxErrors int) Sequence[T] {
+	r := make(Sequence[T])
+	spawn("ErrorThreshold", nil, func() {
+		defer close(r)
+		budget := errorBudget{max: maxErrors}
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				if err := budget.add(item.Error); err != nil {
+					send(ctx, r, Fail[T](err))
+					return
+				}
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+		}
+	})
+	return r
+}
+
+// errorBudget collects errors up to a maximum
+type errorBudget struct {
+	max  int
+	errs []error
+}
+
+// add records the given error. Once the maximum is reached, it returns the
+// error that terminates the sequence.
+func (b *errorBudget) add(err error) error {
+	if b.max <= 0 {
+		return nil
+	}
+	b.errs = append(b.errs, err)
+	if len(b.errs) < b.max {
+		return nil
+	}
+	return fmt.Errorf("%w (%d): %w", ErrTooManyErrors, len(b.errs), errors.Join(b.errs...))
+}
@@ -0,0 +1,114 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Executor is a Runner backed by a pool of long-lived worker goroutines. It
+// avoids the cost of creating a goroutine per task when issuing many small
+// actions. Use it with WithRunner or DoOn; the semantics of Do stay exactly
+// the same, only the goroutine executing the action differs.
+//
+// If all workers are busy and the queue is full, a task is started on a new
+// goroutine instead of waiting, so submitting never blocks and nested tasks
+// cannot deadlock the pool.
+type Executor struct {
+	jobs    chan func()
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+	// defaults caches the options of DoOn invocations without options
+	defaults atomic.Pointer[executorOptions]
+}
+
+// executorOptions are the package defaults base with the executor as runner
+type executorOptions struct {
+	base *options
+	o    *options
+}
+
+var _ Runner = (*Executor)(nil)
+
+// NewExecutor starts an executor with the given number of workers, at least
+// one. The workers run until Close is called.
+func NewExecutor(workers int) *Executor {
+	workers = max(workers, 1)
+	e := &Executor{jobs: make(chan func(), workers)}
+	e.workers.Add(workers)
+	for range workers {
+		go e.work()
+	}
+	return e
+}
+
+func (e *Executor) work() {
+	defer e.workers.Done()
+	for job := range e.jobs {
+		job()
+	}
+}
+
+// Go executes fn on a worker of the pool, or on a new goroutine if the pool
+// is saturated or closed.
+func (e *Executor) Go(fn func()) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.closed {
+		select {
+		case e.jobs <- fn:
+			return
+		default:
+		}
+	}
+	go fn()
+}
+
+// Close stops the workers after the queued tasks were executed and waits for
+// them to exit. Tasks submitted afterwards run on new goroutines.
+func (e *Executor) Close() {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.jobs)
+	}
+	e.mu.Unlock()
+	e.workers.Wait()
+}
+
+// DoOn executes the given action like Do, on a worker of the given executor.
+// It is equivalent to Do with WithRunner(e), but the options of invocations
+// without options are built once per executor, so that DoOn allocates no more
+// than Do.
+//
+// Example:
+//
+//	pool := NewExecutor(runtime.GOMAXPROCS(0))
+//	defer pool.Close()
+//	r := <-DoOn(ctx, pool, func(ctx context.Context) (uint64, error) {
+//	    return checksum(block), nil
+//	})
+func DoOn[T any](ctx context.Context, e *Executor, action func(ctx context.Context) (T, error), opts ...Option) Result[T] {
+	var o *options
+	if len(opts) == 0 {
+		o = e.options()
+	} else {
+		o = buildOptions(opts)
+		o.runner = e
+	}
+	return doFinally(ctx, o, callSite(o), action, nil)
+}
+
+// options returns the package defaults with the executor as runner. They are
+// rebuilt when the package defaults changed.
+func (e *Executor) options() *options {
+	base := newOptions(nil)
+	if c := e.defaults.Load(); c != nil && c.base == base {
+		return c.o
+	}
+	o := *base
+	o.runner = e
+	e.defaults.Store(&executorOptions{base: base, o: &o})
+	return &o
+}
@@ -0,0 +1,124 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+type ctxKey struct{}
+
+func TestDoOn(t *testing.T) {
+	pool := async.NewExecutor(2)
+	defer pool.Close()
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	r := async.DoOn(ctx, pool, func(ctx context.Context) (string, error) {
+		return ctx.Value(ctxKey{}).(string), nil
+	})
+	if item := <-r; item.Value != "value" || item.Error != nil {
+		t.Fatalf("want the action executed with the context, got %v", item)
+	}
+	if _, ok := <-r; ok {
+		t.Fatal("want the result closed after its value")
+	}
+
+	r = async.DoOn(ctx, pool, func(ctx context.Context) (string, error) {
+		panic("boom")
+	}, async.WithRecover())
+	var perr *async.PanicError
+	if item := <-r; !errors.As(item.Error, &perr) {
+		t.Fatalf("want the panic recovered, got %v", item.Error)
+	}
+}
+
+func TestExecutorSaturated(t *testing.T) {
+	pool := async.NewExecutor(1)
+	defer pool.Close()
+	ctx := context.Background()
+	release := make(chan struct{})
+	var blocked []async.Result[int]
+	for range 5 {
+		blocked = append(blocked, async.DoOn(ctx, pool, func(ctx context.Context) (int, error) {
+			<-release
+			return 1, nil
+		}))
+	}
+	// submitting never blocks while the pool is busy
+	asynctest.ExpectError(t, ctx, async.DoOn(ctx, pool, succeed), nil)
+	close(release)
+	for _, r := range blocked {
+		asynctest.ExpectError(t, ctx, r, nil)
+	}
+}
+
+// spin busy waits for d, like a tiny CPU bound action
+func spin(d time.Duration) {
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
+
+func tinyAction(ctx context.Context) (int, error) {
+	spin(time.Microsecond)
+	return 1, nil
+}
+
+// recurse uses about 64 KiB of stack, which a new goroutine has to grow
+// into, while the workers of an executor keep their grown stacks
+//
+//go:noinline
+func recurse(n int) int {
+	var frame [256]byte
+	frame[n%len(frame)] = byte(n)
+	if n == 0 {
+		return 0
+	}
+	return recurse(n-1) + int(frame[n%len(frame)])
+}
+
+func deepStackAction(ctx context.Context) (int, error) {
+	return recurse(200), nil
+}
+
+// BenchmarkDoVsExecutor compares Do with DoOn. Both allocate the same per
+// call, DoOn saves the creation of a goroutine and the growth of its stack.
+func BenchmarkDoVsExecutor(b *testing.B) {
+	ctx := context.Background()
+	actions := []struct {
+		name   string
+		action func(ctx context.Context) (int, error)
+	}{
+		{"tiny", tinyAction},
+		{"deepstack", deepStackAction},
+	}
+	for _, a := range actions {
+		for _, parallelism := range []int{1, 8, 64} {
+			b.Run(fmt.Sprintf("Do/%s/parallelism=%d", a.name, parallelism), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetParallelism(parallelism)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						<-async.Do(ctx, a.action)
+					}
+				})
+			})
+			b.Run(fmt.Sprintf("Executor/%s/parallelism=%d", a.name, parallelism), func(b *testing.B) {
+				pool := async.NewExecutor(runtime.GOMAXPROCS(0))
+				defer pool.Close()
+				b.ReportAllocs()
+				b.SetParallelism(parallelism)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						<-async.DoOn(ctx, pool, a.action)
+					}
+				})
+			})
+		}
+	}
+}
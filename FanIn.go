@@ -0,0 +1,98 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// OrderedFanIn emits the outcomes of the given Results strictly in the order
+// the Results were passed, regardless of the order they complete in. A Result
+// that completes early waits in its channel until it is its turn, so a slow
+// first Result delays all others, while the order of submission is kept.
+//
+// The returned sequence is closed after all Results settled. A Result that is
+// closed without a value is skipped. If the context is done before, a final
+// error item with ctx.Err() is emitted, so the consumer learns that outcomes
+// were abandoned, unless it does not receive at all.
+//
+// Example:
+//
+//	var results []Result[Page]
+//	for _, url := range urls {
+//	    results = append(results, Do(ctx, fetch(url)))
+//	}
+//	for page := range OrderedFanIn(ctx, results...) {
+//	    log.Printf("received: %v", page.Value)
+//	}
+func OrderedFanIn[T any](ctx context.Context, rs ...Result[T]) Sequence[T] {
+	r := make(Sequence[T], 1)
+	spawn("OrderedFanIn", nil, func() {
+		defer close(r)
+		for _, result := range rs {
+			var item _Result[T]
+			var ok bool
+			select {
+			case item, ok = <-result:
+			case <-ctx.Done():
+				abandoned(ctx, r)
+				return
+			}
+			if ok && !send(ctx, r, item) {
+				abandoned(ctx, r)
+				return
+			}
+		}
+	})
+	return r
+}
+
+// UnorderedFanIn emits the outcomes of the given Results in the order they
+// complete.
+//
+// The returned sequence is closed after all Results settled. A Result that is
+// closed without a value is skipped. If the context is done before, a final
+// error item with ctx.Err() is emitted, so the consumer learns that outcomes
+// were abandoned, unless it does not receive at all.
+//
+// Example:
+//
+//	for page := range UnorderedFanIn(ctx, Do(ctx, fetchA), Do(ctx, fetchB)) {
+//	    log.Printf("received: %v", page.Value)
+//	}
+func UnorderedFanIn[T any](ctx context.Context, rs ...Result[T]) Sequence[T] {
+	r := make(Sequence[T], 1)
+	var settled atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(len(rs))
+	for _, result := range rs {
+		spawn("UnorderedFanIn", nil, func() {
+			defer wg.Done()
+			select {
+			case item, ok := <-result:
+				if !ok || send(ctx, r, item) {
+					settled.Add(1)
+				}
+			case <-ctx.Done():
+			}
+		})
+	}
+	spawn("UnorderedFanIn.close", nil, func() {
+		defer close(r)
+		wg.Wait()
+		if settled.Load() < int64(len(rs)) {
+			abandoned(ctx, r)
+		}
+	})
+	return r
+}
+
+// abandoned emits ctx.Err() as final item without blocking. The sequences of
+// the fan-in functions have a buffer of one item, so this only fails if the
+// consumer did not even receive the previous item.
+func abandoned[T any](ctx context.Context, r Sequence[T]) {
+	select {
+	case r <- Fail[T](ctxError(ctx)):
+	default:
+	}
+}
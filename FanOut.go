@@ -0,0 +1,91 @@
+package async
+
+import (
+	"context"
+	"hash/maphash"
+)
+
+// FanOut splits the input across n copies of the worker function and merges
+// their outputs. In contrast to a stage that maps single items, every worker
+// is a streaming transformation of its own sub-sequence, so it can hold state
+// for its lifetime, such as a connection or a cache.
+//
+// The successful items are distributed round-robin. Error items of the input
+// are not passed to a worker but forwarded to the output directly. Use
+// FanOutBy to route related items to the same worker instead.
+//
+// Every worker must consume its sub-sequence until it is closed, a worker
+// that stops early blocks the distribution until the context is done.
+//
+// The returned sequence is closed when all workers closed their outputs or
+// the context is done.
+//
+// Example:
+//
+//	results := FanOut(ctx, jobs, 4, func(ctx context.Context, in Sequence[Job]) Sequence[Report] {
+//	    conn := pool.Get()
+//	    return Map(ctx, in, func(ctx context.Context, job Job) (Report, error) {
+//	        return conn.Run(ctx, job)
+//	    })
+//	})
+func FanOut[T, U any](ctx context.Context, in Sequence[T], n int, worker func(ctx context.Context, in Sequence[T]) Sequence[U]) Sequence[U] {
+	next := 0
+	return fanOut(ctx, in, n, worker, func(T) int {
+		i := next
+		next = (next + 1) % max(n, 1)
+		return i
+	})
+}
+
+// FanOutBy is like FanOut, but distributes the successful items by the key
+// returned by key: all items with the same key are passed to the same worker,
+// e.g. to keep the items of a customer in order.
+//
+// Example:
+//
+//	results := FanOutBy(ctx, orders, 8, func(o Order) string {
+//	    return o.CustomerID
+//	}, processOrders)
+func FanOutBy[T, U any, K comparable](ctx context.Context, in Sequence[T], n int, key func(v T) K, worker func(ctx context.Context, in Sequence[T]) Sequence[U]) Sequence[U] {
+	seed := maphash.MakeSeed()
+	return fanOut(ctx, in, n, worker, func(v T) int {
+		return int(maphash.Comparable(seed, key(v)) % uint64(max(n, 1)))
+	})
+}
+
+// fanOut starts n workers and distributes the input with pick
+func fanOut[T, U any](ctx context.Context, in Sequence[T], n int, worker func(ctx context.Context, in Sequence[T]) Sequence[U], pick func(v T) int) Sequence[U] {
+	n = max(n, 1)
+	subs := make([]Sequence[T], n)
+	outs := make([]Sequence[U], n+1)
+	for i := range subs {
+		subs[i] = make(Sequence[T])
+		outs[i] = worker(ctx, subs[i])
+	}
+	errs := make(Sequence[U])
+	outs[n] = errs
+	spawn("FanOut", nil, func() {
+		defer func() {
+			for _, sub := range subs {
+				close(sub)
+			}
+			close(errs)
+		}()
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				if !send(ctx, errs, Fail[U](item.Error)) {
+					return
+				}
+				continue
+			}
+			if !send(ctx, subs[pick(item.Value)], item) {
+				return
+			}
+		}
+	})
+	return Merge(ctx, outs...)
+}
@@ -0,0 +1,45 @@
+package async
+
+import (
+	"context"
+)
+
+// Filter forwards the successful items of the input for which keep returns
+// true and drops the others. Error items are always forwarded.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithName
+//   - WithOnDrop
+//
+// Example:
+//
+//	adults := Filter(ctx, users, func(u User) bool {
+//	    return u.Age >= 18
+//	})
+func Filter[T any](ctx context.Context, in Sequence[T], keep func(v T) bool, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Filter", o, func() {
+		defer close(r)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error == nil && !keep(item.Value) {
+				if o.onDrop != nil {
+					o.onDrop()
+				}
+				continue
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+			stageEmitted(o)
+		}
+	})
+	return r
+}
@@ -0,0 +1,104 @@
+package async
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrResultClosed is returned by First2 and First3 if all given Results are
+// closed without a value, i.e. they were received from before.
+var ErrResultClosed = errors.New("async: result closed without a value")
+
+// Either2 is the outcome of First2. Index tells which Result settled first,
+// 0 for the first and 1 for the second, and the field of that Result holds its
+// value. Error is the error of that Result.
+type Either2[A, B any] struct {
+	Index  int
+	First  A
+	Second B
+	Error  error
+}
+
+// Either3 is the outcome of First3, see Either2.
+type Either3[A, B, C any] struct {
+	Index  int
+	First  A
+	Second B
+	Third  C
+	Error  error
+}
+
+// First2 waits for whichever of two Results of possibly different types
+// settles first, e.g. a Result with data and one signalling shutdown. The
+// Result that did not settle is left untouched, the caller can still wait for
+// it later.
+//
+// A Result that is closed without a value, because it was received from
+// before, is ignored. If both are, ErrResultClosed is returned. If the context
+// is done before one of them settled, the error of the context is returned,
+// see CancelError.
+//
+// Example:
+//
+//	outcome, err := First2(ctx, Do(ctx, fetch), shutdown)
+//	if err != nil {
+//	    return err
+//	}
+//	if outcome.Index == 1 {
+//	    return errShuttingDown
+//	}
+//	use(outcome.First)
+func First2[A, B any](ctx context.Context, a Result[A], b Result[B]) (Either2[A, B], error) {
+	for a != nil || b != nil {
+		select {
+		case item, ok := <-a:
+			if !ok {
+				a = nil
+				continue
+			}
+			return Either2[A, B]{Index: 0, First: item.Value, Error: item.Error}, nil
+		case item, ok := <-b:
+			if !ok {
+				b = nil
+				continue
+			}
+			return Either2[A, B]{Index: 1, Second: item.Value, Error: item.Error}, nil
+		case <-ctx.Done():
+			return Either2[A, B]{}, ctxError(ctx)
+		}
+	}
+	return Either2[A, B]{}, ErrResultClosed
+}
+
+// First3 is First2 for three Results.
+//
+// Example:
+//
+//	outcome, err := First3(ctx, primary, fallback, timeout)
+func First3[A, B, C any](ctx context.Context, a Result[A], b Result[B], c Result[C]) (Either3[A, B, C], error) {
+	for a != nil || b != nil || c != nil {
+		select {
+		case item, ok := <-a:
+			if !ok {
+				a = nil
+				continue
+			}
+			return Either3[A, B, C]{Index: 0, First: item.Value, Error: item.Error}, nil
+		case item, ok := <-b:
+			if !ok {
+				b = nil
+				continue
+			}
+			return Either3[A, B, C]{Index: 1, Second: item.Value, Error: item.Error}, nil
+		case item, ok := <-c:
+			if !ok {
+				c = nil
+				continue
+			}
+			return Either3[A, B, C]{Index: 2, Third: item.Value, Error: item.Error}, nil
+		case <-ctx.Done():
+			return Either3[A, B, C]{}, ctxError(ctx)
+		}
+	}
+	return Either3[A, B, C]{}, ErrResultClosed
+}
@@ -0,0 +1,138 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the settled or pending outcome of a Result that can be consumed
+// many times: awaited with Get by any number of goroutines, or observed with
+// callbacks. Its methods are safe for concurrent use. Create it with Share.
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+
+	mu       sync.Mutex
+	settled  bool
+	draining bool
+	// queue holds the callbacks that did not run yet, in registration order
+	queue []func()
+}
+
+// Share consumes the Result and makes its outcome available as Future. A
+// Result that is closed without a value settles the Future with
+// ErrResultClosed.
+//
+// Example:
+//
+//	user := Share(Do(ctx, loadUser))
+//	user.OnSuccess(cache.Put)
+//	user.OnFailure(func(err error) {
+//	    log.Printf("loading user failed: %v", err)
+//	})
+//	u, err := user.Get(ctx)
+func Share[T any](r Result[T]) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	spawn("Future", nil, func() {
+		item, ok := <-r
+		if !ok {
+			item = Fail[T](ErrResultClosed)
+		}
+		f.settle(item)
+	})
+	return f
+}
+
+// Done returns a channel that is closed once the Future settled
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get waits for the Future to settle and returns its outcome. If the context
+// is done before, the error of the context is returned.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctxError(ctx)
+	}
+}
+
+// OnSuccess registers a callback that receives the value if the Future
+// settles successfully, see OnSettled.
+func (f *Future[T]) OnSuccess(fn func(v T)) {
+	f.OnSettled(func(v T, err error) {
+		if err == nil {
+			fn(v)
+		}
+	})
+}
+
+// OnFailure registers a callback that receives the error if the Future
+// settles with an error, see OnSettled.
+func (f *Future[T]) OnFailure(fn func(err error)) {
+	f.OnSettled(func(v T, err error) {
+		if err != nil {
+			fn(err)
+		}
+	})
+}
+
+// OnSettled registers a callback that receives the outcome of the Future.
+// Every callback runs exactly once: the callbacks registered before the
+// Future settled run on the goroutine that settles it, a callback registered
+// afterwards runs right away on the registering goroutine. Either way the
+// callbacks of a Future run one at a time in registration order. A panic of
+// a callback is recovered and discarded, so it does not prevent the
+// following callbacks from running.
+func (f *Future[T]) OnSettled(fn func(v T, err error)) {
+	f.mu.Lock()
+	f.queue = append(f.queue, func() {
+		fn(f.value, f.err)
+	})
+	if !f.settled || f.draining {
+		f.mu.Unlock()
+		return
+	}
+	f.draining = true
+	f.mu.Unlock()
+	f.drain()
+}
+
+// settle records the outcome and runs the callbacks registered so far
+func (f *Future[T]) settle(item _Result[T]) {
+	f.value, f.err = item.Value, item.Error
+	close(f.done)
+	f.mu.Lock()
+	f.settled, f.draining = true, true
+	f.mu.Unlock()
+	f.drain()
+}
+
+// drain runs the queued callbacks until the queue is empty
+func (f *Future[T]) drain() {
+	for {
+		f.mu.Lock()
+		if len(f.queue) == 0 {
+			f.draining = false
+			f.mu.Unlock()
+			return
+		}
+		fn := f.queue[0]
+		f.queue[0] = nil
+		f.queue = f.queue[1:]
+		f.mu.Unlock()
+		runCallback(fn)
+	}
+}
+
+// runCallback runs fn and discards a panic
+func runCallback(fn func()) {
+	defer func() {
+		_ = recover()
+	}()
+	fn()
+}
@@ -0,0 +1,119 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// awaitAll calls Get of the future from n goroutines and returns the outcomes
+func awaitAll[T any](f *async.Future[T], n int) ([]T, []error) {
+	values := make([]T, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			values[i], errs[i] = f.Get(context.Background())
+		})
+	}
+	wg.Wait()
+	return values, errs
+}
+
+func TestFutureSharedValue(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	f := async.Share(async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}))
+	close(release)
+	values, errs := awaitAll(f, 8)
+	if !slices.Equal(values, []int{42, 42, 42, 42, 42, 42, 42, 42}) || slices.ContainsFunc(errs, func(err error) bool { return err != nil }) {
+		t.Fatalf("want every awaiter to get 42, got %v and %v", values, errs)
+	}
+	// awaiting the settled future again delivers the same outcome
+	if v, err := f.Get(context.Background()); v != 42 || err != nil || calls.Load() != 1 {
+		t.Fatalf("want 42 from a single run, got %v, %v after %d runs", v, err, calls.Load())
+	}
+}
+
+func TestFutureSharedCancellation(t *testing.T) {
+	errStopped := errors.New("stopped")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	started := make(chan struct{})
+	f := async.Share(async.Do(ctx, func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}))
+	<-started
+	cancel(errStopped)
+	_, errs := awaitAll(f, 8)
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, errStopped) {
+			t.Fatalf("want every awaiter to get the cancellation, got %v", errs)
+		}
+	}
+	if _, err := f.Get(context.Background()); err != errs[0] {
+		t.Fatalf("want the same error after settling, got %v", err)
+	}
+}
+
+func TestFutureGetDone(t *testing.T) {
+	r := make(async.Result[int])
+	defer close(r)
+	f := async.Share(r)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want the error of the context, got %v", err)
+	}
+	select {
+	case <-f.Done():
+		t.Fatal("want the future pending after an awaiter gave up")
+	default:
+	}
+}
+
+func TestFutureClosedResult(t *testing.T) {
+	r := make(async.Result[int])
+	close(r)
+	if _, err := async.Share(r).Get(context.Background()); !errors.Is(err, async.ErrResultClosed) {
+		t.Fatalf("want ErrResultClosed, got %v", err)
+	}
+}
+
+func TestFutureCallbacks(t *testing.T) {
+	r := make(async.Result[string], 1)
+	f := async.Share(r)
+	var mu sync.Mutex
+	var calls []string
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, s)
+	}
+	f.OnSuccess(func(v string) { record("success " + v) })
+	f.OnSettled(func(v string, err error) { panic("discarded") })
+	f.OnFailure(func(err error) { record("failure") })
+	f.OnSettled(func(v string, err error) { record("settled " + v) })
+	r <- async.Success("a")
+	<-f.Done()
+	f.OnSuccess(func(v string) { record("late " + v) })
+
+	eventually(t, "all callbacks ran", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == 3
+	})
+	if !slices.Equal(calls, []string{"success a", "settled a", "late a"}) {
+		t.Fatalf("want the callbacks in registration order, got %v", calls)
+	}
+}
@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Heartbeat forwards all items of the input and emits a pulse on the
+// returned channel every interval while the sequence is alive, even when no
+// items flow. A supervisor watching the pulses can tell a sequence with
+// nothing to emit from one that is stuck: the pulses stop while forwarding an
+// item blocks, i.e. the consumer does not keep up, and after the sequence
+// ended. To detect a producer that does not deliver items in time, see
+// Watchdog.
+//
+// The pulses are sent without blocking on a channel with a buffer of one, so
+// a supervisor that does not receive never slows down the items. The pulse
+// channel is closed when the returned sequence is closed.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	items, pulses := Heartbeat(ctx, Stream(ctx, poll), time.Second)
+//	go supervise(pulses, 5*time.Second)
+//	for result := range items {
+//	    handle(result)
+//	}
+func Heartbeat[T any](ctx context.Context, in Sequence[T], interval time.Duration, opts ...Option) (Sequence[T], <-chan time.Time) {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	pulses := make(chan time.Time, 1)
+	spawn("Heartbeat", o, func() {
+		defer close(pulses)
+		defer close(r)
+		ticker := o.clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok || !send(ctx, r, item) {
+					return
+				}
+			case now := <-ticker.C():
+				select {
+				case pulses <- now:
+				default:
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return r, pulses
+}
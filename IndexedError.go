@@ -0,0 +1,75 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IndexedError is an error that belongs to the item with the given index of
+// a Sequence. The stages transforming the items of an input wrap every error
+// item into it: Map, Stage, TimeoutEach and RetryEach, as well as Enumerate.
+// The index can be extracted with errors.As. An error item of the input that
+// already carries an index is forwarded unchanged, so in a chain of stages
+// the index of the first stage wins, which identifies the item that caused
+// the failure. Stream constructors such as Stream and StreamConcurrent have no
+// input, their errors are not wrapped.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+func (e *IndexedError) Error() string {
+	return fmt.Sprintf("async: item %d: %v", e.Index, e.Err)
+}
+
+func (e *IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// IndexedErrors joins the failures of Total items, see JoinErrors. Its
+// message lists the failed indexes compactly, e.g. "3 of 20 failed: [2, 7,
+// 19]". errors.Is and errors.As reach every contained error.
+type IndexedErrors struct {
+	Total  int
+	Errors []*IndexedError
+}
+
+func (e *IndexedErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "async: %d of %d failed: [", len(e.Errors), e.Total)
+	for i, err := range e.Errors {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprint(&b, err.Index)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func (e *IndexedErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// indexError wraps the given error with the position of the item it belongs to
+func indexError(index int, err error) error {
+	return &IndexedError{Index: index, Err: err}
+}
+
+// indexInputError wraps the error of an item of the input with the position
+// of the item, unless an upstream stage already did
+func indexInputError(index int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var indexed *IndexedError
+	if errors.As(err, &indexed) {
+		return err
+	}
+	return indexError(index, err)
+}
@@ -0,0 +1,133 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// indexedInput returns a closed sequence of the values 0 to 9, with an error
+// item at index 5
+func indexedInput() async.Sequence[int] {
+	s := make(async.Sequence[int], 10)
+	for i := range 10 {
+		if i == 5 {
+			s <- async.Fail[int](errDownstream)
+			continue
+		}
+		s <- async.Success(i)
+	}
+	close(s)
+	return s
+}
+
+// failOn3And7 fails for the values 3 and 7
+func failOn3And7(ctx context.Context, v int) (int, error) {
+	if v == 3 || v == 7 {
+		return 0, errDownstream
+	}
+	return v, nil
+}
+
+// TestIndexedErrorContract checks that every stage transforming the items
+// of an input wraps all of its error items with the index of the item
+func TestIndexedErrorContract(t *testing.T) {
+	stages := map[string]func(ctx context.Context, in async.Sequence[int]) async.Sequence[int]{
+		"Map": func(ctx context.Context, in async.Sequence[int]) async.Sequence[int] {
+			return async.Map(ctx, in, failOn3And7)
+		},
+		"Stage": func(ctx context.Context, in async.Sequence[int]) async.Sequence[int] {
+			return async.Stage("check", 4, failOn3And7)(ctx, in)
+		},
+		"TimeoutEach": func(ctx context.Context, in async.Sequence[int]) async.Sequence[int] {
+			return async.TimeoutEach(ctx, in, time.Minute, failOn3And7)
+		},
+		"RetryEach": func(ctx context.Context, in async.Sequence[int]) async.Sequence[int] {
+			return async.RetryEach(ctx, in, 2, nil, failOn3And7)
+		},
+		// Filter drops the first two values, so Map numbers the items
+		// differently than RetryEach
+		"Map of filtered RetryEach": func(ctx context.Context, in async.Sequence[int]) async.Sequence[int] {
+			kept := async.Filter(ctx, async.RetryEach(ctx, in, 2, nil, failOn3And7), func(v int) bool { return v >= 2 })
+			return async.Map(ctx, kept, func(ctx context.Context, v int) (int, error) { return v, nil })
+		},
+	}
+	for name, stage := range stages {
+		t.Run(name, func(t *testing.T) {
+			_, errs := items(stage(context.Background(), indexedInput()))
+			var indexes []int
+			for _, err := range errs {
+				var indexed *async.IndexedError
+				if !errors.As(err, &indexed) {
+					t.Fatalf("want every error wrapped into an IndexedError, got %v", err)
+				}
+				if !errors.Is(err, errDownstream) {
+					t.Fatalf("want the original error reachable, got %v", err)
+				}
+				indexes = append(indexes, indexed.Index)
+			}
+			slices.Sort(indexes)
+			if !slices.Equal(indexes, []int{3, 5, 7}) {
+				t.Fatalf("want errors at the indexes [3 5 7], got %v", indexes)
+			}
+		})
+	}
+}
+
+func TestIndexedErrorEnumerate(t *testing.T) {
+	_, errs := items(async.Enumerate(context.Background(), indexedInput(), async.WithCountErrors()))
+	var indexed *async.IndexedError
+	if len(errs) != 1 || !errors.As(errs[0], &indexed) || indexed.Index != 5 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the error item wrapped with the index 5, got %v", errs)
+	}
+}
+
+func TestIndexedErrorsJoined(t *testing.T) {
+	ctx := context.Background()
+	in := make(async.Sequence[int], 20)
+	for i := range 20 {
+		in <- async.Success(i)
+	}
+	close(in)
+	seq := async.Stage("check", 4, func(ctx context.Context, v int) (int, error) {
+		if v == 19 || v == 2 || v == 7 {
+			return 0, errDownstream
+		}
+		return v, nil
+	})(ctx, in)
+	err := async.JoinErrors(ctx, seq)
+	var joined *async.IndexedErrors
+	if !errors.As(err, &joined) {
+		t.Fatalf("want the indexed errors joined into IndexedErrors, got %v", err)
+	}
+	if got, want := err.Error(), "async: 3 of 20 failed: [2, 7, 19]"; got != want {
+		t.Fatalf("want message %q, got %q", want, got)
+	}
+	var indexed *async.IndexedError
+	if !errors.As(err, &indexed) || indexed.Index != 2 || !errors.Is(err, errDownstream) {
+		t.Fatalf("want the contained errors reachable, got %v", indexed)
+	}
+}
+
+func TestIndexedErrorsMixed(t *testing.T) {
+	ctx := context.Background()
+	in := make(async.Sequence[int], 2)
+	in <- async.Fail[int](&async.IndexedError{Index: 1, Err: errDownstream})
+	in <- async.Fail[int](errDownstream)
+	close(in)
+	var joined *async.IndexedErrors
+	if err := async.JoinErrors(ctx, in); errors.As(err, &joined) || !errors.Is(err, errDownstream) {
+		t.Fatalf("want errors without index joined plainly, got %v", err)
+	}
+}
+
+func TestIndexedErrorMessage(t *testing.T) {
+	err := &async.IndexedError{Index: 5234, Err: errDownstream}
+	if got, want := err.Error(), "async: item 5234: downstream failed"; got != want {
+		t.Fatalf("want message %q, got %q", want, got)
+	}
+}
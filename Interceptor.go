@@ -0,0 +1,70 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Interceptor wraps the execution of an async action to add cross-cutting
+// behavior like logging, metrics or refreshing credentials. It receives the
+// next function of the chain and returns the function that replaces it.
+//
+// An interceptor may short-circuit the chain by returning an error without
+// calling next. The name given with WithName is available to interceptors via
+// TaskName on the context.
+//
+// Interceptors are applied by Do to the action and by Stream and StreamState
+// to every invocation of the step function. If an interceptor short-circuits a
+// step, the stream ends after emitting the error.
+//
+// Example:
+//
+//	async.Use(func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error) {
+//	    return func(ctx context.Context) (any, error) {
+//	        start := time.Now()
+//	        v, err := next(ctx)
+//	        log.Printf("%s took %v", async.TaskName(ctx), time.Since(start))
+//	        return v, err
+//	    }
+//	})
+type Interceptor func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error)
+
+var (
+	defaultInterceptorsMu sync.RWMutex
+	defaultInterceptors   []Interceptor
+)
+
+// Use registers interceptors that are applied to every Do and Stream
+// invocation of the process, before the interceptors given with
+// WithInterceptors. Interceptors run in registration order, the first one
+// registered is the outermost.
+func Use(interceptors ...Interceptor) {
+	defaultInterceptorsMu.Lock()
+	defer defaultInterceptorsMu.Unlock()
+	defaultInterceptors = append(defaultInterceptors[:len(defaultInterceptors):len(defaultInterceptors)], interceptors...)
+	resetDefaultOptions()
+}
+
+// registeredInterceptors returns the interceptors registered with Use
+func registeredInterceptors() []Interceptor {
+	defaultInterceptorsMu.RLock()
+	defer defaultInterceptorsMu.RUnlock()
+	return defaultInterceptors
+}
+
+// intercept runs fn wrapped by the interceptors of the given options
+func intercept[T any](ctx context.Context, o *options, fn func(ctx context.Context) (T, error)) (T, error) {
+	chain := o.interceptors
+	if len(chain) == 0 {
+		return fn(ctx)
+	}
+	next := func(ctx context.Context) (any, error) {
+		return fn(ctx)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		next = chain[i](next)
+	}
+	value, err := next(ctx)
+	result, _ := value.(T)
+	return result, err
+}
@@ -0,0 +1,110 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// trace records the calls of the interceptors of a test
+type trace struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (tr *trace) add(call string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.calls = append(tr.calls, call)
+}
+
+func (tr *trace) get() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return slices.Clone(tr.calls)
+}
+
+// recording returns an interceptor that records entering and leaving next
+func (tr *trace) recording(name string) async.Interceptor {
+	return func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) {
+			tr.add(name + " before")
+			v, err := next(ctx)
+			tr.add(name + " after")
+			return v, err
+		}
+	}
+}
+
+type traceKey struct{}
+
+var registerOnce sync.Once
+
+// useRecording registers an interceptor with Use, once for the whole test
+// binary, that records the tasks whose context carries a trace
+func useRecording() {
+	registerOnce.Do(func() {
+		async.Use(func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error) {
+			return func(ctx context.Context) (any, error) {
+				tr, ok := ctx.Value(traceKey{}).(*trace)
+				if !ok {
+					return next(ctx)
+				}
+				return tr.recording("use")(next)(ctx)
+			}
+		})
+	})
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	useRecording()
+	tr := &trace{}
+	ctx := context.WithValue(context.Background(), traceKey{}, tr)
+	r := <-async.Do(ctx, func(ctx context.Context) (int, error) {
+		tr.add("action")
+		return 1, nil
+	}, async.WithInterceptors(tr.recording("first"), tr.recording("second")))
+	if r.Value != 1 || r.Error != nil {
+		t.Fatalf("want the value of the action, got %v", r)
+	}
+	want := []string{"use before", "first before", "second before", "action", "second after", "first after", "use after"}
+	if got := tr.get(); !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	errDenied := errors.New("denied")
+	deny := func(next func(ctx context.Context) (any, error)) func(ctx context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) {
+			return nil, errDenied
+		}
+	}
+	called := false
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		called = true
+		return 1, nil
+	}, async.WithInterceptors(deny))
+	if called || !errors.Is(r.Error, errDenied) {
+		t.Fatalf("want the action skipped with the error of the interceptor, got %v", r)
+	}
+}
+
+func TestInterceptorStream(t *testing.T) {
+	tr := &trace{}
+	values, errs := items(async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		tr.add("step")
+		return 1, nil, len(tr.get()) < 5
+	}, async.WithInterceptors(tr.recording("outer"))))
+	if len(values) != 2 || errs != nil {
+		t.Fatalf("want two items, got %v and %v", values, errs)
+	}
+	want := []string{"outer before", "step", "outer after", "outer before", "step", "outer after"}
+	if got := tr.get(); !slices.Equal(got, want) {
+		t.Fatalf("want every step intercepted, got %v", got)
+	}
+}
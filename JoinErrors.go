@@ -0,0 +1,80 @@
+package async
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+)
+
+// JoinErrors consumes the given sequence purely for its failures. It blocks
+// until the sequence is closed and returns errors.Join of all error items, or
+// nil if there were none. Successful items are discarded.
+//
+// If every error item carries an IndexedError, e.g. because the sequence was
+// produced by Map or Stage, an *IndexedErrors sorted by index is returned
+// instead, whose message lists the failed indexes out of all items received.
+//
+// If the context is done before the sequence is closed, the consumption stops
+// and ctx.Err() is joined with the errors collected so far.
+//
+// Example:
+//
+//	if err := JoinErrors(ctx, Stream(ctx, sendNextNotification)); err != nil {
+//	    log.Printf("some notifications failed: %v", err)
+//	}
+func JoinErrors[T any](ctx context.Context, in Sequence[T]) error {
+	var errs []error
+	var indexed []*IndexedError
+	var ie *IndexedError
+	total := 0
+	for {
+		item, ok := receive(ctx, in)
+		if !ok {
+			err := errors.Join(errs...)
+			if len(errs) > 0 && len(indexed) == len(errs) {
+				slices.SortFunc(indexed, func(a, b *IndexedError) int { return cmp.Compare(a.Index, b.Index) })
+				err = &IndexedErrors{Total: total, Errors: indexed}
+			}
+			if cerr := ctxError(ctx); cerr != nil {
+				return errors.Join(err, cerr)
+			}
+			return err
+		}
+		total++
+		if item.Error != nil {
+			errs = append(errs, item.Error)
+			// stop looking for indexes after the first error without one
+			if len(indexed) == len(errs)-1 && errors.As(item.Error, &ie) {
+				indexed = append(indexed, ie)
+			}
+		}
+	}
+}
+
+// FirstError consumes the given sequence until its first error item and
+// returns that error, or nil if the sequence is closed without an error.
+// Successful items are discarded.
+//
+// The rest of the sequence is abandoned once an error was found, so the
+// producer should be bound to a context that is cancelled afterwards. If the
+// context is done before, ctx.Err() is returned.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	if err := FirstError(ctx, Stream(ctx, writeNextChunk)); err != nil {
+//	    return err
+//	}
+func FirstError[T any](ctx context.Context, in Sequence[T]) error {
+	for {
+		item, ok := receive(ctx, in)
+		if !ok {
+			return ctxError(ctx)
+		}
+		if item.Error != nil {
+			return item.Error
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// failures returns a closed sequence of n error items between values
+func failures(n int) async.Sequence[int] {
+	s := make(async.Sequence[int], 2*n)
+	for i := range n {
+		s <- async.Success(i)
+		s <- async.Fail[int](fmt.Errorf("item %d: %w", i, errDownstream))
+	}
+	close(s)
+	return s
+}
+
+func TestJoinErrorsThousands(t *testing.T) {
+	const n = 10000
+	err := async.JoinErrors(context.Background(), failures(n))
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != n {
+		t.Fatalf("want %d joined errors, got %v", n, err)
+	}
+	if !errors.Is(err, errDownstream) {
+		t.Fatalf("want the joined error to match the item errors")
+	}
+
+	// collecting the errors must not allocate per error
+	const runs = 5
+	ins := make([]async.Sequence[int], 0, runs+1)
+	for range runs + 1 {
+		ins = append(ins, failures(n))
+	}
+	allocs := testing.AllocsPerRun(runs, func() {
+		_ = async.JoinErrors(context.Background(), ins[0])
+		ins = ins[1:]
+	})
+	if allocs > 100 {
+		t.Fatalf("want a bounded number of allocations for %d errors, got %v", n, allocs)
+	}
+}
+
+func TestJoinErrorsNone(t *testing.T) {
+	if err := async.JoinErrors(context.Background(), seqOf(1, 2, 3)); err != nil {
+		t.Fatalf("want nil without error items, got %v", err)
+	}
+}
+
+func TestJoinErrorsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(async.Sequence[int], 1)
+	in <- async.Fail[int](errDownstream)
+	cancel()
+	err := async.JoinErrors(ctx, in)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want the error of the context joined, got %v", err)
+	}
+}
+
+func TestFirstError(t *testing.T) {
+	ctx := context.Background()
+	in := failures(3)
+	err := async.FirstError(ctx, in)
+	if err == nil || err.Error() != "item 0: "+errDownstream.Error() {
+		t.Fatalf("want the first error, got %v", err)
+	}
+	if len(in) != 4 {
+		t.Fatalf("want the rest of the sequence abandoned, %d items are left", len(in))
+	}
+	if err := async.FirstError(ctx, seqOf(1, 2)); err != nil {
+		t.Fatalf("want nil without error items, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := async.FirstError(cancelled, make(async.Sequence[int])); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want the error of the context, got %v", err)
+	}
+}
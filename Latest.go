@@ -0,0 +1,92 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// LatestValue holds the newest value of a sequence, see Latest.
+type LatestValue[T any] struct {
+	mu      sync.Mutex
+	value   T
+	err     error
+	ok      bool
+	changed chan struct{}
+	done    chan struct{}
+}
+
+// Latest drains the input continuously and retains only its most recent
+// successful value and its most recent error. Intermediate items are
+// conflated, so a slow reader never holds back the producer. This is the
+// consumption model for config watchers and market data like streams, where
+// only the newest value matters.
+//
+// The draining goroutine exits when the input is closed or the context is
+// done, the last values remain available afterwards.
+//
+// Example:
+//
+//	config := Latest(ctx, Stream(ctx, watchConfig))
+//	for {
+//	    changed := config.Changed()
+//	    if cfg, _, ok := config.Get(); ok {
+//	        apply(cfg)
+//	    }
+//	    select {
+//	    case <-changed:
+//	    case <-config.Done():
+//	        return
+//	    }
+//	}
+func Latest[T any](ctx context.Context, in Sequence[T]) *LatestValue[T] {
+	l := &LatestValue[T]{
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	spawn("Latest", nil, func() {
+		defer close(l.done)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			l.set(item)
+		}
+	})
+	return l
+}
+
+// Get returns the most recent successful value and the most recent error of
+// the input. ok is false until the first item arrived.
+func (l *LatestValue[T]) Get() (value T, err error, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.value, l.err, l.ok
+}
+
+// Changed returns a channel that is closed when the next item arrives. A new
+// channel has to be obtained after every change. Obtain it before calling Get,
+// so a change between Get and the wait is not missed.
+func (l *LatestValue[T]) Changed() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.changed
+}
+
+// Done returns a channel that is closed when the draining goroutine exited
+func (l *LatestValue[T]) Done() <-chan struct{} {
+	return l.done
+}
+
+func (l *LatestValue[T]) set(item _Result[T]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if item.Error != nil {
+		l.err = item.Error
+	} else {
+		l.value = item.Value
+	}
+	l.ok = true
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
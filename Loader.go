@@ -0,0 +1,137 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is the error of a Loader result whose key is missing in the
+// map returned by the batch function.
+var ErrNotFound = errors.New("async: key not found")
+
+// Loader coalesces individual loads into batch calls, collapsing N+1 fetch
+// patterns into a single round trip. It is safe for concurrent use. Create it
+// with NewLoader.
+type Loader[K comparable, V any] struct {
+	batch    func(ctx context.Context, keys []K) (map[K]V, error)
+	maxBatch int
+	maxWait  time.Duration
+	o        *options
+
+	mu      sync.Mutex
+	pending *loaderBatch[K, V]
+}
+
+// loaderBatch collects the keys of a window
+type loaderBatch[K comparable, V any] struct {
+	ctx     context.Context
+	keys    []K
+	waiters map[K][]*waiter[V]
+	// full is closed when the batch reached the maximum size
+	full chan struct{}
+}
+
+// NewLoader creates a loader that passes the keys of all loads within maxWait
+// after the first one to a single call of batch. A batch is dispatched early
+// once it holds maxBatch distinct keys, zero or negative means batches are
+// only limited by time.
+//
+// The batch function is invoked on its own goroutine with a context that
+// carries the values of the context of the first load of the batch, but is
+// not cancelled with it.
+//
+// Supported options:
+//   - WithClock
+//   - WithName
+//
+// Example:
+//
+//	users := NewLoader(func(ctx context.Context, ids []int) (map[int]*User, error) {
+//	    return db.UsersByID(ctx, ids)
+//	}, 100, 2*time.Millisecond)
+//	author, reviewer := users.Load(ctx, pr.AuthorID), users.Load(ctx, pr.ReviewerID)
+func NewLoader[K comparable, V any](batch func(ctx context.Context, keys []K) (map[K]V, error), maxBatch int, maxWait time.Duration, opts ...Option) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:    batch,
+		maxBatch: maxBatch,
+		maxWait:  maxWait,
+		o:        newOptions(opts),
+	}
+}
+
+// Load adds the key to the current batch. The returned Result resolves with
+// the value of the key, with the error of the batch, with ErrNotFound if the
+// batch did not return the key, or with the error of the context if it is
+// done first. Loads of the same key within a window share a single entry of
+// the batch.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Result[V] {
+	if err := ctx.Err(); err != nil {
+		return resolved(Fail[V](ctxError(ctx)))
+	}
+	w := &waiter[V]{r: make(Result[V], 1)}
+	w.stop = context.AfterFunc(ctx, func() {
+		w.resolve(Fail[V](ctxError(ctx)))
+	})
+	l.mu.Lock()
+	b, first := l.pending, l.pending == nil
+	if first {
+		b = &loaderBatch[K, V]{
+			ctx:     context.WithoutCancel(ctx),
+			waiters: map[K][]*waiter[V]{},
+			full:    make(chan struct{}),
+		}
+		l.pending = b
+	}
+	if _, ok := b.waiters[key]; !ok {
+		b.keys = append(b.keys, key)
+	}
+	b.waiters[key] = append(b.waiters[key], w)
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+		l.pending = nil
+		close(b.full)
+	}
+	l.mu.Unlock()
+	if first {
+		l.schedule(b)
+	}
+	return w.r
+}
+
+// schedule dispatches the batch once its window elapsed or it is full
+func (l *Loader[K, V]) schedule(b *loaderBatch[K, V]) {
+	spawn("Loader", l.o, func() {
+		timer := l.o.clock.NewTimer(l.maxWait)
+		select {
+		case <-timer.C():
+			l.mu.Lock()
+			if l.pending == b {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+		case <-b.full:
+			timer.Stop()
+		}
+		l.dispatch(b)
+	})
+}
+
+// dispatch invokes the batch function and distributes its outcome
+func (l *Loader[K, V]) dispatch(b *loaderBatch[K, V]) {
+	values, err := l.batch(b.ctx, b.keys)
+	for key, waiters := range b.waiters {
+		item := Fail[V](err)
+		if err == nil {
+			if v, ok := values[key]; ok {
+				item = Success(v)
+			} else {
+				item = Fail[V](ErrNotFound)
+			}
+		}
+		for _, w := range waiters {
+			w.resolve(item)
+			w.stop()
+		}
+	}
+}
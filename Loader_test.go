@@ -0,0 +1,143 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// squares is a batch function returning the square of every key except 13,
+// recording the keys of its calls
+type squares struct {
+	mu    sync.Mutex
+	calls [][]int
+	err   error
+}
+
+func (s *squares) batch(ctx context.Context, keys []int) (map[int]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, slices.Clone(keys))
+	if s.err != nil {
+		return nil, s.err
+	}
+	values := map[int]int{}
+	for _, k := range keys {
+		if k != 13 {
+			values[k] = k * k
+		}
+	}
+	return values, nil
+}
+
+func (s *squares) recorded() [][]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+func expectLoaded(t *testing.T, r async.Result[int], want int) {
+	t.Helper()
+	if item := <-r; item.Value != want || item.Error != nil {
+		t.Fatalf("want the loaded value %d, got %v", want, item)
+	}
+}
+
+func TestLoaderBatchesAndDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &squares{}
+	loader := async.NewLoader(source.batch, 0, time.Millisecond, async.WithClock(clock))
+
+	a, b, c, d := loader.Load(ctx, 1), loader.Load(ctx, 2), loader.Load(ctx, 1), loader.Load(ctx, 3)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	expectLoaded(t, a, 1)
+	expectLoaded(t, b, 4)
+	expectLoaded(t, c, 1)
+	expectLoaded(t, d, 9)
+	if calls := source.recorded(); len(calls) != 1 || !slices.Equal(calls[0], []int{1, 2, 3}) {
+		t.Fatalf("want a single batch of the distinct keys, got %v", calls)
+	}
+
+	// the next load starts a new window
+	e := loader.Load(ctx, 1)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	expectLoaded(t, e, 1)
+	if calls := source.recorded(); len(calls) != 2 {
+		t.Fatalf("want a batch per window, got %v", calls)
+	}
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &squares{}
+	loader := async.NewLoader(source.batch, 2, time.Millisecond, async.WithClock(clock))
+
+	// a full batch is dispatched without waiting for the window
+	a, b, again := loader.Load(ctx, 1), loader.Load(ctx, 2), loader.Load(ctx, 3)
+	expectLoaded(t, a, 1)
+	expectLoaded(t, b, 4)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	expectLoaded(t, again, 9)
+	if calls := source.recorded(); !slices.EqualFunc(calls, [][]int{{1, 2}, {3}}, slices.Equal) {
+		t.Fatalf("want the batches split at the maximum size, got %v", calls)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &squares{}
+	loader := async.NewLoader(source.batch, 0, time.Millisecond, async.WithClock(clock))
+
+	found, missing := loader.Load(ctx, 2), loader.Load(ctx, 13)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	expectLoaded(t, found, 4)
+	if item := <-missing; !errors.Is(item.Error, async.ErrNotFound) {
+		t.Fatalf("want ErrNotFound for the missing key only, got %v", item)
+	}
+
+	source.err = errDownstream
+	first, second := loader.Load(ctx, 1), loader.Load(ctx, 2)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	for _, r := range []async.Result[int]{first, second} {
+		if item := <-r; !errors.Is(item.Error, errDownstream) {
+			t.Fatalf("want the batch error for every key, got %v", item)
+		}
+	}
+}
+
+func TestLoaderCancelledWaiter(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &squares{}
+	loader := async.NewLoader(source.batch, 0, time.Millisecond, async.WithClock(clock))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	gone := loader.Load(cancelled, 2)
+	kept := loader.Load(ctx, 2)
+	cancel()
+	if item := <-gone; !errors.Is(item.Error, context.Canceled) {
+		t.Fatalf("want the cancelled load to fail, got %v", item)
+	}
+
+	// the batch runs for the other load of the same key
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	expectLoaded(t, kept, 4)
+	if calls := source.recorded(); len(calls) != 1 {
+		t.Fatalf("want the batch executed, got %v", calls)
+	}
+}
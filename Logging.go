@@ -0,0 +1,110 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// LogLevels are the levels the task lifecycle is logged at
+type LogLevels struct {
+	// Start is the level of the message logged when a task starts
+	Start slog.Level
+	// Complete is the level of the message logged when a task completed
+	// without error or a stream ended
+	Complete slog.Level
+	// Error is the level of the messages logged when a task failed or
+	// panicked
+	Error slog.Level
+}
+
+// DefaultLogLevels are the levels used unless WithLogLevels is given
+var DefaultLogLevels = LogLevels{
+	Start:    slog.LevelDebug,
+	Complete: slog.LevelInfo,
+	Error:    slog.LevelError,
+}
+
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// SetLogger sets the logger used for the task lifecycle of every Do and
+// Stream of the process that has no logger given with WithLogger. Passing nil
+// disables logging, which is the default.
+func SetLogger(l *slog.Logger) {
+	defaultLogger.Store(l)
+}
+
+// levels returns the levels the task lifecycle is logged at
+func (o *options) levels() LogLevels {
+	if o.logLevels != nil {
+		return *o.logLevels
+	}
+	return DefaultLogLevels
+}
+
+// logger returns the logger of the task or nil
+func (o *options) logger() *slog.Logger {
+	if o.log != nil {
+		return o.log
+	}
+	return defaultLogger.Load()
+}
+
+// taskAttrs are the attributes identifying the task in every message
+func taskAttrs(t *taskRun) []slog.Attr {
+	return infoAttrs(&t.info, slog.String("task_kind", string(t.kind)))
+}
+
+// infoAttrs appends the attributes of the given task metadata
+func infoAttrs(info *taskInfo, attrs ...slog.Attr) []slog.Attr {
+	attrs = append(attrs, slog.String("task_name", info.name), slog.String("task_id", formatTaskID(info.id)))
+	if info.parent != 0 {
+		attrs = append(attrs, slog.String("parent_task_id", formatTaskID(info.parent)))
+	}
+	return attrs
+}
+
+func (o *options) logStart(t *taskRun) {
+	l := o.logger()
+	if l == nil {
+		return
+	}
+	l.LogAttrs(t.ctx, o.levels().Start, "async task started", taskAttrs(t)...)
+}
+
+func (o *options) logEnd(t *taskRun, d time.Duration, err error, reason CloseReason) {
+	l := o.logger()
+	if l == nil {
+		return
+	}
+	attrs := append(taskAttrs(t), slog.Duration("duration", d))
+	if reason != "" {
+		attrs = append(attrs, slog.String("reason", string(reason)))
+	}
+	var perr *PanicError
+	switch {
+	case errors.As(err, &perr):
+		attrs = append(attrs, slog.Any("error", err), slog.String("stack", string(perr.Stack)))
+		l.LogAttrs(t.ctx, o.levels().Error, "async task panicked", attrs...)
+	case err != nil && (t.kind == kindDo || reason != CloseCompleted):
+		attrs = append(attrs, slog.Any("error", err))
+		l.LogAttrs(t.ctx, o.levels().Error, "async task failed", attrs...)
+	default:
+		l.LogAttrs(t.ctx, o.levels().Complete, "async task completed", attrs...)
+	}
+}
+
+// logPanic logs a panic that is not recovered and will crash the process
+func (o *options) logPanic(ctx context.Context, perr *PanicError) {
+	l := o.logger()
+	if l == nil {
+		return
+	}
+	attrs := []slog.Attr{slog.Any("error", perr), slog.String("stack", string(perr.Stack))}
+	if info := currentTask(ctx); info != nil {
+		attrs = infoAttrs(info, attrs...)
+	}
+	l.LogAttrs(ctx, o.levels().Error, "async task panicked", attrs...)
+}
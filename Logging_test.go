@@ -0,0 +1,113 @@
+package async_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// logBuffer collects the JSON records of a logger
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records decodes the records logged so far
+func (b *logBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	for line := range strings.Lines(b.buf.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// logged returns a debug logger writing to a new buffer
+func logged() (*slog.Logger, *logBuffer) {
+	b := &logBuffer{}
+	return slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug})), b
+}
+
+// expectRecord fails unless the record has the given attributes
+func expectRecord(t *testing.T, record map[string]any, attrs map[string]any) {
+	t.Helper()
+	for key, want := range attrs {
+		if got := record[key]; got != want {
+			t.Fatalf("want %s=%v, got %v in %v", key, want, got, record)
+		}
+	}
+}
+
+func TestLoggingDo(t *testing.T) {
+	logger, b := logged()
+	var id string
+	<-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		id = async.TaskID(ctx)
+		return 0, errors.New("failed")
+	}, async.WithLogger(logger), async.WithName("sync"))
+	records := b.records(t)
+	if len(records) != 2 {
+		t.Fatalf("want a start and an end record, got %v", records)
+	}
+	expectRecord(t, records[0], map[string]any{
+		"level": "DEBUG", "msg": "async task started", "task_kind": "Do", "task_name": "sync", "task_id": id,
+	})
+	expectRecord(t, records[1], map[string]any{
+		"level": "ERROR", "msg": "async task failed", "task_kind": "Do", "task_name": "sync", "task_id": id, "error": "failed",
+	})
+	if _, ok := records[1]["duration"]; !ok {
+		t.Fatalf("want the duration logged, got %v", records[1])
+	}
+	if _, ok := records[1]["parent_task_id"]; ok {
+		t.Fatalf("want no parent of a top-level task, got %v", records[1])
+	}
+}
+
+func TestLoggingStream(t *testing.T) {
+	logger, b := logged()
+	levels := async.LogLevels{Start: slog.LevelInfo, Complete: slog.LevelWarn, Error: slog.LevelError}
+	for range async.Stream(context.Background(), counter(), async.WithLogger(logger), async.WithLogLevels(levels), async.WithMaxIterations(2)) {
+	}
+	records := b.records(t)
+	if len(records) != 2 {
+		t.Fatalf("want a start and an end record, got %v", records)
+	}
+	expectRecord(t, records[0], map[string]any{"level": "INFO", "task_kind": "Stream", "task_name": ""})
+	expectRecord(t, records[1], map[string]any{
+		"level": "ERROR", "msg": "async task failed", "reason": string(async.CloseLimitExceeded),
+	})
+}
+
+func TestLoggingPanic(t *testing.T) {
+	logger, b := logged()
+	<-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		panic("boom")
+	}, async.WithLogger(logger), async.WithRecover())
+	records := b.records(t)
+	if len(records) != 2 {
+		t.Fatalf("want a start and an end record, got %v", records)
+	}
+	expectRecord(t, records[1], map[string]any{"level": "ERROR", "msg": "async task panicked", "error": "async: panic: boom"})
+	if stack, _ := records[1]["stack"].(string); !strings.Contains(stack, "TestLoggingPanic") {
+		t.Fatalf("want the stack of the panic logged, got %v", records[1])
+	}
+}
@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+)
+
+// Map transforms every successful item of the input with the given function.
+// An error returned by f is emitted as error item in place of the value, the
+// stage continues with the next item. Errors returned by f and error items of
+// the input are wrapped with the index of the item into an *IndexedError.
+// Every item of the input, including error items, advances the index.
+//
+// Every invocation of f runs with the instrumentation of Do: the hooks,
+// interceptors and panic handling given with the options, labelled with the
+// name set with WithName.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithInterceptors
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRecover
+//
+// Example:
+//
+//	users := Map(ctx, ids, func(ctx context.Context, id int) (User, error) {
+//	    return repo.Get(ctx, id)
+//	}, WithName("load-user"))
+func Map[T, U any](ctx context.Context, in Sequence[T], f func(ctx context.Context, v T) (U, error), opts ...Option) Sequence[U] {
+	o := newOptions(opts)
+	r := make(Sequence[U])
+	spawn("Map", o, func() {
+		defer close(r)
+		for index := 0; ; index++ {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			out := Fail[U](indexInputError(index, item.Error))
+			if item.Error == nil {
+				value, err := execute(ctx, o, func(ctx context.Context) (U, error) {
+					return f(ctx, item.Value)
+				})
+				out = Success(value)
+				if err != nil {
+					out = Fail[U](indexError(index, err))
+				}
+			}
+			if !send(ctx, r, out) {
+				return
+			}
+			stageEmitted(o)
+		}
+	})
+	return r
+}
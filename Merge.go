@@ -0,0 +1,39 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge forwards the items of all given sequences on a single sequence, in
+// the order they arrive. Error items are forwarded as they are.
+//
+// The returned sequence is closed when all inputs are closed or the context is
+// done.
+//
+// Example:
+//
+//	for result := range Merge(ctx, Stream(ctx, pollEU), Stream(ctx, pollUS)) {
+//	    log.Printf("received: %v", result.Value)
+//	}
+func Merge[T any](ctx context.Context, ins ...Sequence[T]) Sequence[T] {
+	r := make(Sequence[T])
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		spawn("Merge", nil, func() {
+			defer wg.Done()
+			for {
+				item, ok := receive(ctx, in)
+				if !ok || !send(ctx, r, item) {
+					return
+				}
+			}
+		})
+	}
+	spawn("Merge.close", nil, func() {
+		wg.Wait()
+		close(r)
+	})
+	return r
+}
@@ -0,0 +1,113 @@
+package async
+
+import (
+	"container/heap"
+	"context"
+)
+
+// MergeSorted merges sequences that are each sorted by less into a single
+// sorted sequence. It performs a k-way merge over the heads of the inputs, so
+// per-shard sorted streams yield a globally sorted stream without collecting
+// them first. An item is emitted as soon as every input that is not exhausted
+// has provided its next item.
+//
+// An error item of an input is forwarded immediately and the input is
+// treated as ended, the merge continues with the remaining inputs. Use
+// MergeSortedAbortOnError to stop the merge at the first error instead. An
+// input that ended with an error is not consumed any further, so its producer
+// should be bound to the context.
+//
+// The returned sequence is closed when all inputs are exhausted or the
+// context is done.
+//
+// Example:
+//
+//	events := MergeSorted(ctx, func(a, b Event) bool {
+//	    return a.Time.Before(b.Time)
+//	}, shardA, shardB, shardC)
+func MergeSorted[T any](ctx context.Context, less func(a, b T) bool, ins ...Sequence[T]) Sequence[T] {
+	return mergeSorted(ctx, less, ins, false)
+}
+
+// MergeSortedAbortOnError is like MergeSorted, but stops the whole merge
+// after forwarding the first error item of any input.
+//
+// The returned sequence is closed when all inputs are exhausted, an error was
+// forwarded or the context is done.
+//
+// Example:
+//
+//	events := MergeSortedAbortOnError(ctx, func(a, b Event) bool {
+//	    return a.Time.Before(b.Time)
+//	}, shardA, shardB, shardC)
+func MergeSortedAbortOnError[T any](ctx context.Context, less func(a, b T) bool, ins ...Sequence[T]) Sequence[T] {
+	return mergeSorted(ctx, less, ins, true)
+}
+
+func mergeSorted[T any](ctx context.Context, less func(a, b T) bool, ins []Sequence[T], abortOnError bool) Sequence[T] {
+	r := make(Sequence[T])
+	spawn("MergeSorted", nil, func() {
+		defer close(r)
+		h := &mergeHeap[T]{less: less}
+		// pull reads the next item of the given input and reports whether
+		// the merge may go on
+		pull := func(src int) bool {
+			item, ok := receive(ctx, ins[src])
+			if !ok {
+				return ctx.Err() == nil
+			}
+			if item.Error != nil {
+				return send(ctx, r, item) && !abortOnError
+			}
+			heap.Push(h, mergeHead[T]{value: item.Value, src: src})
+			return true
+		}
+		for src := range ins {
+			if !pull(src) {
+				return
+			}
+		}
+		for h.Len() > 0 {
+			head := heap.Pop(h).(mergeHead[T])
+			if !send(ctx, r, Success(head.value)) || !pull(head.src) {
+				return
+			}
+		}
+	})
+	return r
+}
+
+// mergeHead is the current item of an input of MergeSorted
+type mergeHead[T any] struct {
+	value T
+	src   int
+}
+
+// mergeHeap implements heap.Interface over the heads of the inputs
+type mergeHeap[T any] struct {
+	heads []mergeHead[T]
+	less  func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.heads) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if h.less(h.heads[i].value, h.heads[j].value) {
+		return true
+	}
+	if h.less(h.heads[j].value, h.heads[i].value) {
+		return false
+	}
+	// equal items are emitted in the order of the inputs
+	return h.heads[i].src < h.heads[j].src
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.heads = append(h.heads, x.(mergeHead[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	last := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return last
+}
@@ -0,0 +1,55 @@
+package async_test
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// failingAfter returns a closed sequence of the given values followed by an
+// error item and a value that must never be read
+func failingAfter(values ...int) async.Sequence[int] {
+	s := make(async.Sequence[int], len(values)+2)
+	for _, v := range values {
+		s <- async.Success(v)
+	}
+	s <- async.Fail[int](errDownstream)
+	s <- async.Success(1000)
+	close(s)
+	return s
+}
+
+func TestMergeSortedUnevenInputs(t *testing.T) {
+	ctx := context.Background()
+	seq := async.MergeSorted(ctx, cmp.Less[int],
+		seqOf(1, 4, 7, 10, 11, 12, 20),
+		seqOf[int](),
+		seqOf(2, 3),
+		seqOf(5),
+		seqOf(4, 6, 8, 9, 13))
+	asynctest.ExpectValues(t, ctx, seq, []int{1, 2, 3, 4, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 20})
+}
+
+func TestMergeSortedEarlyError(t *testing.T) {
+	ctx := context.Background()
+	values, errs := items(async.MergeSorted(ctx, cmp.Less[int], seqOf(1, 3, 5, 7), failingAfter(2), seqOf(4, 6)))
+	if !slices.Equal(values, []int{1, 2, 3, 4, 5, 6, 7}) {
+		t.Fatalf("want the merge continued without the failed input, got %v", values)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the error forwarded once, got %v", errs)
+	}
+}
+
+func TestMergeSortedAbortOnError(t *testing.T) {
+	ctx := context.Background()
+	values, errs := items(async.MergeSortedAbortOnError(ctx, cmp.Less[int], seqOf(1, 3, 5, 7), failingAfter(2), seqOf(4, 6)))
+	if !slices.Equal(values, []int{1, 2}) || len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the merge stopped at the error, got %v and %v", values, errs)
+	}
+}
@@ -0,0 +1,130 @@
+package async
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives the lifecycle events of the goroutines started by Do and
+// Stream, keyed by the name given with WithName. Implementations must be safe
+// for concurrent use and fast, they are called synchronously.
+type Metrics interface {
+	// TaskStarted is called when the goroutine of a Do or Stream starts
+	TaskStarted(name string)
+	// TaskFinished is called when the goroutine of a Do or Stream ends. err
+	// is the error of the result or the last item, nil on success.
+	TaskFinished(name string, d time.Duration, err error)
+	// ItemsEmitted is called when a Stream or a stage named with WithName
+	// emitted n items
+	ItemsEmitted(name string, n int)
+}
+
+type metricsHolder struct {
+	m Metrics
+}
+
+var metrics atomic.Pointer[metricsHolder]
+
+// SetMetrics registers the metrics receiver of the process. Passing nil
+// disables metrics, which is the default and costs nothing.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&metricsHolder{m: m})
+}
+
+// currentMetrics returns the registered metrics receiver or nil
+func currentMetrics() Metrics {
+	if h := metrics.Load(); h != nil {
+		return h.m
+	}
+	return nil
+}
+
+// stageEmitted reports an item forwarded by a stage named with WithName
+func stageEmitted(o *options) {
+	if o.name == "" {
+		return
+	}
+	if m := currentMetrics(); m != nil {
+		m.ItemsEmitted(o.name, 1)
+	}
+}
+
+// Counters is a Metrics implementation based on atomic counters. It can be
+// registered with SetMetrics and inspected with Snapshot.
+type Counters struct {
+	tasks sync.Map
+}
+
+// TaskCounters is the snapshot of the counters of a single task name
+type TaskCounters struct {
+	// InFlight is the number of goroutines currently running
+	InFlight int64
+	// Started is the total number of started goroutines
+	Started int64
+	// Completed is the total number of goroutines finished without error
+	Completed int64
+	// Failed is the total number of goroutines finished with an error
+	Failed int64
+	// Items is the total number of items emitted by streams
+	Items int64
+}
+
+type taskCounters struct {
+	inFlight, started, completed, failed, items atomic.Int64
+}
+
+// NewCounters creates an empty set of counters
+func NewCounters() *Counters {
+	return &Counters{}
+}
+
+func (c *Counters) get(name string) *taskCounters {
+	if t, ok := c.tasks.Load(name); ok {
+		return t.(*taskCounters)
+	}
+	t, _ := c.tasks.LoadOrStore(name, &taskCounters{})
+	return t.(*taskCounters)
+}
+
+func (c *Counters) TaskStarted(name string) {
+	t := c.get(name)
+	t.started.Add(1)
+	t.inFlight.Add(1)
+}
+
+func (c *Counters) TaskFinished(name string, d time.Duration, err error) {
+	t := c.get(name)
+	t.inFlight.Add(-1)
+	if err != nil {
+		t.failed.Add(1)
+	} else {
+		t.completed.Add(1)
+	}
+}
+
+func (c *Counters) ItemsEmitted(name string, n int) {
+	c.get(name).items.Add(int64(n))
+}
+
+// Snapshot returns the current counters per task name. Tasks without a name
+// are reported under the empty string.
+func (c *Counters) Snapshot() map[string]TaskCounters {
+	snapshot := map[string]TaskCounters{}
+	c.tasks.Range(func(key, value any) bool {
+		t := value.(*taskCounters)
+		snapshot[key.(string)] = TaskCounters{
+			InFlight:  t.inFlight.Load(),
+			Started:   t.started.Load(),
+			Completed: t.completed.Load(),
+			Failed:    t.failed.Load(),
+			Items:     t.items.Load(),
+		}
+		return true
+	})
+	return snapshot
+}
@@ -0,0 +1,62 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// withCounters registers fresh counters for the duration of the test
+func withCounters(t *testing.T) *async.Counters {
+	c := async.NewCounters()
+	async.SetMetrics(c)
+	t.Cleanup(func() {
+		async.SetMetrics(nil)
+	})
+	return c
+}
+
+func TestMetricsDo(t *testing.T) {
+	c := withCounters(t)
+	ctx := context.Background()
+	for _, err := range []error{nil, nil, errors.New("failed")} {
+		<-async.Do(ctx, func(ctx context.Context) (int, error) {
+			return 0, err
+		}, async.WithName("metrics.do"))
+	}
+	got := c.Snapshot()["metrics.do"]
+	want := async.TaskCounters{Started: 3, Completed: 2, Failed: 1}
+	if got != want {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestMetricsStream(t *testing.T) {
+	c := withCounters(t)
+	ctx := context.Background()
+	release := make(chan struct{})
+	seq := async.Stream(ctx, func(ctx context.Context) (int, error, bool) {
+		<-release
+		return 1, nil, false
+	}, async.WithName("metrics.stream"))
+	eventually(t, "the stream is in flight", func() bool {
+		return c.Snapshot()["metrics.stream"].InFlight == 1
+	})
+	close(release)
+	for range seq {
+	}
+	got := c.Snapshot()["metrics.stream"]
+	if want := (async.TaskCounters{Started: 1, Completed: 1, Items: 1}); got != want {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+
+	for range async.Map(ctx, seqOf(1, 2, 3), func(ctx context.Context, v int) (int, error) {
+		return v, nil
+	}, async.WithName("metrics.map")) {
+	}
+	if got := c.Snapshot()["metrics.map"].Items; got != 3 {
+		t.Fatalf("want 3 items forwarded by the named stage, got %d", got)
+	}
+}
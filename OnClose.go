@@ -0,0 +1,82 @@
+package async
+
+import (
+	"context"
+)
+
+// CloseReason is the reason a sequence ended, see WithOnClose and OnClose. It
+// is also logged with the end of a Stream.
+type CloseReason string
+
+const (
+	// CloseCompleted means the step function or the input ended the sequence
+	CloseCompleted CloseReason = "completed"
+	// CloseStoppedOnError means the sequence ended after an error item, e.g.
+	// because of WithStopOnError
+	CloseStoppedOnError CloseReason = "stopped-on-error"
+	// CloseCancelled means the context was done before the next item was
+	// produced
+	CloseCancelled CloseReason = "cancelled"
+	// CloseLimitExceeded means the sequence was cut short by WithMaxErrors,
+	// WithMaxIterations or WithMaxDuration
+	CloseLimitExceeded CloseReason = "limit-exceeded"
+	// CloseConsumerStopped means the context was done while an item waited
+	// for the consumer, i.e. the consumer stopped receiving
+	CloseConsumerStopped CloseReason = "consumer-stopped"
+	// ClosePanicked means a step of a Stream panicked and WithRecover turned
+	// the panic into the last item, a *PanicError
+	ClosePanicked CloseReason = "panicked"
+)
+
+// OnClose forwards the input and invokes fn exactly once when the returned
+// sequence ends, with the reason it ended. This attaches a finalizer to
+// sequences not created with Stream, see WithOnClose for those.
+//
+// The reason is CloseCompleted if the input was closed, or
+// CloseStoppedOnError if its last item was an error item. It is
+// CloseCancelled if the context is done while waiting for the input and
+// CloseConsumerStopped if it is done while waiting for the consumer. fn is
+// invoked on the goroutine of OnClose after the returned sequence was closed,
+// also if the consumer never received an item.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithName
+//
+// Example:
+//
+//	sub := broker.Subscribe(ctx, "orders")
+//	orders := OnClose(ctx, sub.Messages(), func(reason CloseReason) {
+//	    sub.Unsubscribe()
+//	})
+func OnClose[T any](ctx context.Context, in Sequence[T], fn func(reason CloseReason), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("OnClose", o, func() {
+		reason := CloseCompleted
+		defer func() {
+			fn(reason)
+		}()
+		defer close(r)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				if ctx.Err() != nil {
+					reason = CloseCancelled
+				}
+				return
+			}
+			if !send(ctx, r, item) {
+				reason = CloseConsumerStopped
+				return
+			}
+			reason = CloseCompleted
+			if item.Error != nil {
+				reason = CloseStoppedOnError
+			}
+		}
+	})
+	return r
+}
@@ -0,0 +1,95 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// closeReason captures the reason passed to a close callback
+func closeReason() (chan async.CloseReason, func(reason async.CloseReason)) {
+	reasons := make(chan async.CloseReason, 1)
+	return reasons, func(reason async.CloseReason) {
+		reasons <- reason
+	}
+}
+
+// expectReason waits for the close callback and checks that it received one
+// of the wanted reasons
+func expectReason(t *testing.T, reasons chan async.CloseReason, want ...async.CloseReason) {
+	t.Helper()
+	select {
+	case got := <-reasons:
+		if !slices.Contains(want, got) {
+			t.Fatalf("want close reason %q, got %q", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("want close reason %q, the callback was not invoked", want)
+	}
+}
+
+func TestWithOnCloseCompleted(t *testing.T) {
+	reasons, onClose := closeReason()
+	n := 0
+	seq := async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		return n, nil, n < 3
+	}, async.WithOnClose(onClose))
+	for range seq {
+	}
+	expectReason(t, reasons, async.CloseCompleted)
+}
+
+func TestWithOnCloseWithoutConsumer(t *testing.T) {
+	for name, start := range map[string]func(ctx context.Context, opts ...async.Option) async.Sequence[int]{
+		"Stream": func(ctx context.Context, opts ...async.Option) async.Sequence[int] {
+			return async.Stream(ctx, func(ctx context.Context) (int, error, bool) {
+				return 1, nil, true
+			}, opts...)
+		},
+		"StreamState": func(ctx context.Context, opts ...async.Option) async.Sequence[int] {
+			return async.StreamState(ctx, 0, func(ctx context.Context, n int) (int, int, error, bool) {
+				return n + 1, n, nil, true
+			}, opts...)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reasons, onClose := closeReason()
+			ctx, cancel := context.WithCancel(context.Background())
+			start(ctx, async.WithOnClose(onClose))
+			cancel()
+			// depending on whether the first item was produced already
+			expectReason(t, reasons, async.CloseCancelled, async.CloseConsumerStopped)
+		})
+	}
+}
+
+func TestOnCloseWrapper(t *testing.T) {
+	reasons, onClose := closeReason()
+	seq := async.OnClose(context.Background(), seqOf(1, 2), onClose)
+	for range seq {
+	}
+	expectReason(t, reasons, async.CloseCompleted)
+
+	reasons, onClose = closeReason()
+	ctx, cancel := context.WithCancel(context.Background())
+	async.OnClose(ctx, seqOf(1, 2), onClose)
+	cancel()
+	expectReason(t, reasons, async.CloseCancelled, async.CloseConsumerStopped)
+}
+
+func TestWithOnClosePanicked(t *testing.T) {
+	reasons, onClose := closeReason()
+	_, errs := items(async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		panic("boom")
+	}, async.WithRecover(), async.WithOnClose(onClose)))
+	var perr *async.PanicError
+	if len(errs) != 1 || !errors.As(errs[0], &perr) {
+		t.Fatalf("want the panic as last item, got %v", errs)
+	}
+	expectReason(t, reasons, async.ClosePanicked)
+}
@@ -0,0 +1,425 @@
+package async
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Option configures the behavior of a function of this package. Every
+// function documents the options it supports, all other options are ignored.
+type Option func(*options)
+
+type options struct {
+	ackTimeout       time.Duration
+	batchFlush       time.Duration
+	batchSize        int
+	blockOnSlow      bool
+	buffer           int
+	checkpointEvery  int
+	clock            Clock
+	countErrors      bool
+	deadLetter       func(value any, err error)
+	errorStacks      bool
+	healthyAfter     time.Duration
+	interceptors     []Interceptor
+	limiter          Limiter
+	log              *slog.Logger
+	logLevels        *LogLevels
+	manualDemand     bool
+	maxDuration      time.Duration
+	maxErrors        int
+	maxIterations    int
+	maxOutstanding   int
+	name             string
+	onClose          func(reason CloseReason)
+	onComplete       func(ctx context.Context, name string, d time.Duration, err error)
+	onDrop           func()
+	onStart          func(ctx context.Context, name string)
+	progressBytes    int64
+	progressInterval time.Duration
+	recover          bool
+	rejectThrottled  bool
+	retryAttempts    int
+	retryBackoff     BackoffFunc
+	runner           Runner
+	sizeHint         int
+	skipErrors       bool
+	spanPerStep      bool
+	stepTimeout      time.Duration
+	stopOnError      bool
+	stopOnSaveError  bool
+	tracer           Tracer
+	tracker          *Tracker
+}
+
+// defaultOptions caches the options of invocations without options. It is
+// reset whenever one of the package defaults changes.
+var defaultOptions atomic.Pointer[options]
+
+// newOptions applies the given options on top of the package defaults. The
+// returned options must not be modified, they may be shared.
+func newOptions(opts []Option) *options {
+	if len(opts) == 0 {
+		if o := defaultOptions.Load(); o != nil {
+			return o
+		}
+		o := buildOptions(nil)
+		defaultOptions.Store(o)
+		return o
+	}
+	return buildOptions(opts)
+}
+
+// resetDefaultOptions drops the cached default options
+func resetDefaultOptions() {
+	defaultOptions.Store(nil)
+}
+
+func buildOptions(opts []Option) *options {
+	o := &options{
+		clock:        currentClock(),
+		interceptors: registeredInterceptors(),
+		runner:       currentRunner(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAckTimeout makes Acked deliver a value again if its delivery was not
+// acknowledged within the given duration. By default Acked waits for Ack or
+// Nack indefinitely.
+func WithAckTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.ackTimeout = d
+	}
+}
+
+// WithBlockOnSlowSubscriber makes ReplaySeq wait for its slowest subscriber
+// before it discards a recorded item, instead of letting the subscriber miss
+// it.
+func WithBlockOnSlowSubscriber() Option {
+	return func(o *options) {
+		o.blockOnSlow = true
+	}
+}
+
+// WithBuffer sets the number of items a Stage buffers between its workers and
+// the next stage. By default the buffer holds one item per worker.
+func WithBuffer(n int) Option {
+	return func(o *options) {
+		o.buffer = n
+	}
+}
+
+// WithCheckpointEvery makes StreamCheckpointed save its state only every n
+// successful steps instead of after every one.
+func WithCheckpointEvery(n int) Option {
+	return func(o *options) {
+		o.checkpointEvery = n
+	}
+}
+
+// WithClock sets the clock used by time based functions, overriding the clock
+// set with SetClock. This is mainly useful to control the time in tests. It
+// is supported by every function of this package that accepts options.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithCountErrors makes Enumerate count error items as well, so that every
+// item of the input advances the index, not only the successful ones.
+func WithCountErrors() Option {
+	return func(o *options) {
+		o.countErrors = true
+	}
+}
+
+// WithDeadLetter sets the callback of Acked for values whose retries are used
+// up. It is invoked with the value and the error of its last delivery, on the
+// goroutine of Acked, so it should not block.
+func WithDeadLetter(fn func(value any, err error)) Option {
+	return func(o *options) {
+		o.deadLetter = fn
+	}
+}
+
+// WithErrorStacks makes Do and Stream wrap every error they deliver into a
+// *StackError, which records where the task was started. The call site is
+// captured once per invocation, which costs roughly 0.7µs and one
+// allocation, plus one for the wrapper of an error, so it is safe to leave on
+// outside of hot loops.
+func WithErrorStacks() Option {
+	return func(o *options) {
+		o.errorStacks = true
+	}
+}
+
+// WithHealthyAfter makes Resubscribe reset its count of restarts once an
+// inner sequence ran for the given duration.
+func WithHealthyAfter(d time.Duration) Option {
+	return func(o *options) {
+		o.healthyAfter = d
+	}
+}
+
+// WithInterceptors adds interceptors to a single Do or Stream invocation.
+// They run after the interceptors registered with Use, in the given order.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors[:len(o.interceptors):len(o.interceptors)], interceptors...)
+	}
+}
+
+// WithName names the task of a Do or Stream invocation. The name is available
+// via TaskName on the context of the action, next to the generated TaskID,
+// and is used by the hooks, logging, metrics and tracing of the package.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithOnClose registers a callback that is invoked exactly once when a Stream
+// ended, with the reason it ended. It runs on the goroutine of the stream
+// after the last item was delivered, so it is the place to release resources
+// the step function opened lazily. It also runs if the consumer never reads
+// an item: with WithOnClose, every stream stops waiting for the consumer and
+// ends once the context is done, even a Stream that is not aware of the
+// context otherwise.
+func WithOnClose(fn func(reason CloseReason)) Option {
+	return func(o *options) {
+		o.onClose = fn
+	}
+}
+
+// WithOnComplete registers a hook that is invoked when the action of Do or a
+// step of Stream returned, with the name given by WithName, the duration of
+// the execution and its error. If the action panics, the hook receives a
+// *PanicError. The hook runs synchronously on the goroutine of the action
+// before the result is delivered, so it must be fast.
+func WithOnComplete(fn func(ctx context.Context, name string, d time.Duration, err error)) Option {
+	return func(o *options) {
+		o.onComplete = fn
+	}
+}
+
+// WithOnDrop registers a callback that is invoked for every item a stage
+// drops on purpose, e.g. Throttle. It makes the loss observable, for example
+// by incrementing a counter. The callback is invoked synchronously by the
+// stage and should return quickly.
+func WithOnDrop(fn func()) Option {
+	return func(o *options) {
+		o.onDrop = fn
+	}
+}
+
+// WithOnStart registers a hook that is invoked before the action of Do or
+// every step of Stream is executed, with the name given by WithName. The hook
+// runs synchronously on the goroutine of the action, so it must be fast.
+func WithOnStart(fn func(ctx context.Context, name string)) Option {
+	return func(o *options) {
+		o.onStart = fn
+	}
+}
+
+// WithProgress sets how often Copy reports its progress: every time another
+// n bytes were copied, or after the given interval elapsed since the last
+// report. Zero disables the respective trigger.
+func WithProgress(n int64, interval time.Duration) Option {
+	return func(o *options) {
+		o.progressBytes = n
+		o.progressInterval = interval
+	}
+}
+
+// WithRateLimit makes Stream and StreamState wait on the given limiter before
+// every invocation of the step function. If waiting fails, the error is
+// emitted as a final error item and the stream ends.
+func WithRateLimit(l Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// WithMaxErrors aborts Stream and StreamState once the given number of error
+// items has been produced, see ErrorThreshold. Zero or less means unlimited.
+func WithMaxErrors(n int) Option {
+	return func(o *options) {
+		o.maxErrors = n
+	}
+}
+
+// WithLogger sets the logger for the task lifecycle of Do and Stream:
+// start, completion with duration, errors, panics with stack and the reason a
+// stream ended. It overrides the logger set with SetLogger.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.log = l
+	}
+}
+
+// WithLogLevels sets the levels the task lifecycle is logged at, see
+// DefaultLogLevels.
+func WithLogLevels(levels LogLevels) Option {
+	return func(o *options) {
+		o.logLevels = &levels
+	}
+}
+
+// WithManualDemand makes StreamPull start without demand and only raise it
+// with Demand.Request, instead of requesting an item for every item received.
+func WithManualDemand() Option {
+	return func(o *options) {
+		o.manualDemand = true
+	}
+}
+
+// WithMaxDuration terminates Stream and StreamState once the given duration
+// elapsed since the first step was started, see Deadline. A step in progress
+// is not interrupted. Like with Deadline, the stream gets a buffer of one item
+// that takes the final error item, so it reaches a slow consumer and the
+// producer exits in time.
+func WithMaxDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.maxDuration = d
+	}
+}
+
+// WithMaxIterations stops Stream and StreamState after the step function was
+// invoked n times. If the last step asked to continue, a final error item
+// wrapping ErrMaxIterations is emitted, so a step function that never stops
+// becomes visible. Zero or less means unlimited.
+func WithMaxIterations(n int) Option {
+	return func(o *options) {
+		o.maxIterations = n
+	}
+}
+
+// WithMaxOutstanding sets the number of deliveries Acked lets await their
+// acknowledgement at a time. It defaults to 64.
+func WithMaxOutstanding(n int) Option {
+	return func(o *options) {
+		o.maxOutstanding = n
+	}
+}
+
+// WithRecover turns a panic of the action of Do or a step of Stream into an
+// error result with a *PanicError, instead of crashing the process. A stream
+// ends after emitting the panic as error item, with the close reason
+// ClosePanicked.
+func WithRecover() Option {
+	return func(o *options) {
+		o.recover = true
+	}
+}
+
+// WithRejectThrottled makes a function returned by ThrottleFunc resolve calls
+// during its cool-down with ErrThrottled instead of the most recent outcome.
+func WithRejectThrottled() Option {
+	return func(o *options) {
+		o.rejectThrottled = true
+	}
+}
+
+// WithSendBatching sets how StreamBatched groups the results of its step
+// function: a batch is sent once it holds n results or, if flushEvery is
+// positive, once flushEvery elapsed since its first result was produced.
+func WithSendBatching(n int, flushEvery time.Duration) Option {
+	return func(o *options) {
+		o.batchSize = n
+		o.batchFlush = flushEvery
+	}
+}
+
+// WithRetry makes Pages fetch a failed page up to the given number of
+// attempts, waiting the delay returned by backoff in between. A nil backoff
+// retries immediately.
+func WithRetry(attempts int, backoff BackoffFunc) Option {
+	return func(o *options) {
+		o.retryAttempts = attempts
+		o.retryBackoff = backoff
+	}
+}
+
+// WithRunner sets the runner that starts the goroutines of a single
+// invocation, overriding the runner set with SetRunner. It is supported by
+// every function of this package that accepts options.
+func WithRunner(r Runner) Option {
+	return func(o *options) {
+		o.runner = r
+	}
+}
+
+// WithSizeHint preallocates the buffer of Sorted and SortedSeq for the given
+// number of values.
+func WithSizeHint(n int) Option {
+	return func(o *options) {
+		o.sizeHint = n
+	}
+}
+
+// WithSkipErrors makes Sorted and SortedSeq drop error items of the input
+// instead of failing on the first one.
+func WithSkipErrors() Option {
+	return func(o *options) {
+		o.skipErrors = true
+	}
+}
+
+// WithSpanPerStep makes Stream and StreamState create a span for every step
+// instead of a single span for the whole stream, see WithTracer.
+func WithSpanPerStep() Option {
+	return func(o *options) {
+		o.spanPerStep = true
+	}
+}
+
+// WithStepTimeout gives every invocation of the step function of Stream a
+// context of its own that expires after d. A step that overruns d yields an
+// error item wrapping context.DeadlineExceeded, with or without a result of
+// its own, which ends the stream only with WithStopOnError. The context is
+// cancelled as soon as the step returned.
+func WithStepTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.stepTimeout = d
+	}
+}
+
+// WithStopOnError stops Stream and StreamState after the first error item was
+// emitted, regardless of whether the step asked to continue.
+func WithStopOnError() Option {
+	return func(o *options) {
+		o.stopOnError = true
+	}
+}
+
+// WithStopOnSaveError stops StreamCheckpointed after a failed save was
+// emitted as error item, instead of going on.
+func WithStopOnSaveError() Option {
+	return func(o *options) {
+		o.stopOnSaveError = true
+	}
+}
+
+// WithTracer makes Do create a span for the action and Stream a span for the
+// whole stream, or one per step with WithSpanPerStep. Spans are named after
+// WithName, or "async.Do" and "async.Stream" respectively.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// WithTracker registers a Do or Stream invocation with the tracker, which
+// counts it until its goroutine ended and cancels its context on Shutdown.
+func WithTracker(t *Tracker) Option {
+	return func(o *options) {
+		o.tracker = t
+	}
+}
@@ -0,0 +1,89 @@
+package async
+
+import (
+	"context"
+)
+
+// pageResult is the outcome of fetching a single page in Pages
+type pageResult[C, T any] struct {
+	items []T
+	next  C
+	more  bool
+}
+
+// Pages streams the items of a cursor paginated source. It calls fetch with
+// the first cursor, emits the items of the page one by one and continues with
+// the next cursor returned by fetch as long as more is true. This replaces
+// the cursor threading every paginated Stream otherwise implements in a
+// closure.
+//
+// An error of fetch ends the sequence with that error as final item. With
+// WithRetry a failed page is fetched again with the same cursor, waiting the
+// delay of the given backoff in between, and the final error is a
+// *RetryError once all attempts failed.
+//
+// Pages is built on StreamState and supports its options, they apply to the
+// pages: the hooks run and the spans are created once per fetched page.
+//
+// Supported options:
+//   - WithClock
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithMaxDuration
+//   - WithMaxIterations
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRateLimit
+//   - WithRecover
+//   - WithRetry
+//   - WithSpanPerStep
+//   - WithTracer
+//
+// Example:
+//
+//	users := Pages(ctx, "", func(ctx context.Context, token string) ([]User, string, bool, error) {
+//	    page, err := api.ListUsers(ctx, token)
+//	    if err != nil {
+//	        return nil, "", false, err
+//	    }
+//	    return page.Users, page.NextToken, page.NextToken != "", nil
+//	}, WithRetry(3, ExponentialBackoff(100*time.Millisecond, time.Second)))
+//	for result := range users {
+//	    log.Printf("user: %v", result.Value)
+//	}
+func Pages[C, T any](ctx context.Context, first C, fetch func(ctx context.Context, cursor C) (items []T, next C, more bool, err error), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	attempts := max(o.retryAttempts, 1)
+	pages := StreamState(ctx, first, func(ctx context.Context, cursor C) (C, []T, error, bool) {
+		page, err := fetchPage(ctx, o, attempts, cursor, fetch)
+		if err != nil {
+			return cursor, nil, err, false
+		}
+		return page.next, page.items, nil, page.more
+	}, opts...)
+	return Unbatch(ctx, pages)
+}
+
+// fetchPage fetches the page at the cursor with the given number of attempts
+func fetchPage[C, T any](ctx context.Context, o *options, attempts int, cursor C, fetch func(ctx context.Context, cursor C) ([]T, C, bool, error)) (pageResult[C, T], error) {
+	for attempt := 1; ; attempt++ {
+		items, next, more, err := fetch(ctx, cursor)
+		if err == nil {
+			return pageResult[C, T]{items: items, next: next, more: more}, nil
+		}
+		if attempts == 1 {
+			return pageResult[C, T]{}, err
+		}
+		if attempt == attempts {
+			return pageResult[C, T]{}, &RetryError{Attempts: attempt, Err: err}
+		}
+		if o.retryBackoff != nil {
+			if o.clock.Sleep(ctx, o.retryBackoff(attempt)) != nil {
+				return pageResult[C, T]{}, ctxError(ctx)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+func ExamplePages() {
+	ctx := context.Background()
+	users := async.Pages(ctx, "", func(ctx context.Context, cursor string) ([]string, string, bool, error) {
+		page, err := listUsers(ctx, cursor)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return page.Items, page.NextCursor, page.NextCursor != "", nil
+	})
+	for result := range users {
+		if result.Error != nil {
+			fmt.Println("error:", result.Error)
+			continue
+		}
+		fmt.Println(result.Value)
+	}
+	// Output:
+	// ada
+	// alan
+	// barbara
+	// donald
+	// edsger
+}
+
+func TestPagesEndsWithError(t *testing.T) {
+	ctx := context.Background()
+	users := async.Pages(ctx, "", func(ctx context.Context, cursor string) ([]string, string, bool, error) {
+		if cursor == "c2" {
+			return nil, "", false, errDownstream
+		}
+		page, _ := listUsers(ctx, cursor)
+		return page.Items, page.NextCursor, true, nil
+	})
+	values, errs := items(users)
+	if !slices.Equal(values, []string{"ada", "alan"}) {
+		t.Fatalf("want the items of the first page, got %v", values)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the error of the failed page as final item, got %v", errs)
+	}
+}
+
+func TestPagesRetriesFailedPage(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	failed := map[string]bool{}
+	users := async.Pages(ctx, "", func(ctx context.Context, cursor string) ([]string, string, bool, error) {
+		if !failed[cursor] {
+			failed[cursor] = true
+			return nil, "", false, errDownstream
+		}
+		page, err := listUsers(ctx, cursor)
+		return page.Items, page.NextCursor, page.NextCursor != "", err
+	}, async.WithRetry(2, async.ConstantBackoff(time.Second)), async.WithClock(clock))
+
+	go func() {
+		// every page fails once and waits for the backoff
+		for range fakeAPI {
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+		}
+	}()
+	values, errs := items(users)
+	if len(values) != 5 || len(errs) != 0 {
+		t.Fatalf("want all items after retrying every page, got %v and %v", values, errs)
+	}
+}
+
+func TestPagesRetriesExhausted(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	users := async.Pages(ctx, "", func(ctx context.Context, cursor string) ([]string, string, bool, error) {
+		return nil, "", false, errDownstream
+	}, async.WithRetry(2, async.ConstantBackoff(time.Second)), async.WithClock(clock))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	_, errs := items(users)
+	var retryErr *async.RetryError
+	if len(errs) != 1 || !errors.As(errs[0], &retryErr) || retryErr.Attempts != 2 {
+		t.Fatalf("want a RetryError after 2 attempts, got %v", errs)
+	}
+}
@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+)
+
+// Pair groups two values of possibly different types.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Pairwise emits every successful item of the input together with its
+// predecessor as Pair{First: previous, Second: current}. The first successful
+// item has no predecessor and therefore produces no output, so an input with
+// a single item results in an empty sequence.
+//
+// Error items are forwarded as they are and do not affect the pairing: the
+// previous value is preserved, so the next successful item is paired with the
+// last successful item before the error.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Example:
+//
+//	for result := range Pairwise(ctx, samples) {
+//	    if result.Error != nil {
+//	        continue
+//	    }
+//	    log.Printf("delta: %v", result.Value.Second-result.Value.First)
+//	}
+func Pairwise[T any](ctx context.Context, in Sequence[T]) Sequence[Pair[T, T]] {
+	r := make(Sequence[Pair[T, T]])
+	spawn("Pairwise", nil, func() {
+		defer close(r)
+		var previous T
+		hasPrevious := false
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				if !send(ctx, r, Fail[Pair[T, T]](item.Error)) {
+					return
+				}
+				continue
+			}
+			if hasPrevious {
+				if !send(ctx, r, Success(Pair[T, T]{First: previous, Second: item.Value})) {
+					return
+				}
+			}
+			previous, hasPrevious = item.Value, true
+		}
+	})
+	return r
+}
@@ -0,0 +1,35 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error an async action or step that panicked is turned
+// into. It carries the recovered value and the stack of the panicking
+// goroutine.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func newPanicError(value any) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("async: panic: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// panicked reports whether err is or wraps a *PanicError
+func panicked(err error) bool {
+	var perr *PanicError
+	return errors.As(err, &perr)
+}
@@ -0,0 +1,85 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// hooked returns the hook options that record their calls to tr
+func hooked(tr *trace) []async.Option {
+	return []async.Option{
+		async.WithOnStart(func(ctx context.Context, name string) {
+			tr.add("start " + name)
+		}),
+		async.WithOnComplete(func(ctx context.Context, name string, d time.Duration, err error) {
+			var perr *async.PanicError
+			switch {
+			case errors.As(err, &perr):
+				tr.add("panic " + name)
+			case err != nil:
+				tr.add("failed " + name)
+			default:
+				tr.add("complete " + name)
+			}
+		}),
+	}
+}
+
+func TestHooksOrder(t *testing.T) {
+	tr := &trace{}
+	opts := append(hooked(tr), async.WithName("load"), async.WithInterceptors(tr.recording("interceptor")))
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		tr.add("action")
+		return 1, nil
+	}, opts...)
+	if r.Error != nil {
+		t.Fatalf("want no error, got %v", r.Error)
+	}
+	want := []string{"start load", "interceptor before", "action", "interceptor after", "complete load"}
+	if got := tr.get(); !slices.Equal(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestHooksStreamSteps(t *testing.T) {
+	tr := &trace{}
+	errStep := errors.New("step failed")
+	n := 0
+	_, errs := items(async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		if n == 2 {
+			return 0, errStep, true
+		}
+		return n, nil, n < 3
+	}, append(hooked(tr), async.WithName("poll"))...))
+	if len(errs) != 1 {
+		t.Fatalf("want one error item, got %v", errs)
+	}
+	want := []string{"start poll", "complete poll", "start poll", "failed poll", "start poll", "complete poll"}
+	if got := tr.get(); !slices.Equal(got, want) {
+		t.Fatalf("want the hooks called per step, got %v", got)
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	tr := &trace{}
+	errCause := errors.New("cause")
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		panic(errCause)
+	}, append(hooked(tr), async.WithName("crash"), async.WithRecover())...)
+	var perr *async.PanicError
+	if !errors.As(r.Error, &perr) || len(perr.Stack) == 0 {
+		t.Fatalf("want a *PanicError with stack, got %v", r.Error)
+	}
+	if !errors.Is(r.Error, errCause) {
+		t.Fatalf("want the panic value unwrapped, got %v", r.Error)
+	}
+	if got := tr.get(); !slices.Equal(got, []string{"start crash", "panic crash"}) {
+		t.Fatalf("want the panic reported to the hooks, got %v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package async
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pipeline is a chain of stages built with Pipe. It is only a description:
+// no goroutine is started until one of the terminal methods Sequence or
+// Collect is invoked. Every method returns a new Pipeline, so a pipeline can
+// be extended in different ways without affecting each other.
+type Pipeline[T any] struct {
+	name   string
+	opts   []Option
+	stages []string
+	build  func(ctx context.Context) Sequence[T]
+}
+
+// Pipe starts a pipeline on the given source, which composes stages fluently
+// instead of nesting the function calls:
+//
+//	Take(ctx, Filter(ctx, Map(ctx, src, parse), valid), 10)
+//
+// becomes
+//
+//	PipeMap(Pipe(src), parse).Filter(valid).Take(10).Sequence(ctx)
+//
+// Same-type stages are methods of Pipeline, type-changing stages are the free
+// functions PipeMap and PipeBatch.
+//
+// Every stage is labelled with a name made of the name of the pipeline, its
+// position and its kind, e.g. "orders.2.Filter", which is passed to the stage
+// with WithName, so that hooks, logging and metrics can tell the stages
+// apart. The options given to Pipe are passed to every stage.
+//
+// Supported options:
+//   - WithInterceptors
+//   - WithName
+//   - WithOnComplete
+//   - WithOnDrop
+//   - WithOnStart
+//   - WithRecover
+//   - WithRunner
+//
+// Example:
+//
+//	users, err := PipeMap(Pipe(ids, WithName("users")), loadUser).
+//	    Filter(func(u User) bool { return u.Active }).
+//	    Take(100).
+//	    Collect(ctx)
+func Pipe[T any](src Sequence[T], opts ...Option) *Pipeline[T] {
+	return &Pipeline[T]{
+		name: newOptions(opts).name,
+		opts: opts,
+		build: func(context.Context) Sequence[T] {
+			return src
+		},
+	}
+}
+
+// Stages returns the names of the stages of the pipeline in order
+func (p *Pipeline[T]) Stages() []string {
+	return append([]string(nil), p.stages...)
+}
+
+// Filter adds a stage that keeps only the items for which keep returns true,
+// see Filter
+func (p *Pipeline[T]) Filter(keep func(v T) bool) *Pipeline[T] {
+	return then(p, "Filter", func(ctx context.Context, in Sequence[T], opts []Option) Sequence[T] {
+		return Filter(ctx, in, keep, opts...)
+	})
+}
+
+// Take adds a stage that forwards only the first n items, see Take
+func (p *Pipeline[T]) Take(n int) *Pipeline[T] {
+	return then(p, "Take", func(ctx context.Context, in Sequence[T], opts []Option) Sequence[T] {
+		return Take(ctx, in, n, opts...)
+	})
+}
+
+// Tap adds a stage that invokes fn for every successful item, see Tap
+func (p *Pipeline[T]) Tap(fn func(v T)) *Pipeline[T] {
+	return then(p, "Tap", func(ctx context.Context, in Sequence[T], opts []Option) Sequence[T] {
+		return Tap(ctx, in, fn, opts...)
+	})
+}
+
+// Buffer adds a stage with a buffer of n items, see Buffer
+func (p *Pipeline[T]) Buffer(n int) *Pipeline[T] {
+	return then(p, "Buffer", func(ctx context.Context, in Sequence[T], opts []Option) Sequence[T] {
+		return Buffer(ctx, in, n, opts...)
+	})
+}
+
+// Sequence starts the goroutines of all stages and returns the output of the
+// last one. The stages stop when the context is done.
+func (p *Pipeline[T]) Sequence(ctx context.Context) Sequence[T] {
+	return p.build(ctx)
+}
+
+// Collect starts the pipeline and collects its output, see Collect. The stages
+// are stopped when Collect returns, the source however is not, so it should be
+// bound to the context as well.
+func (p *Pipeline[T]) Collect(ctx context.Context) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return Collect(ctx, p.build(ctx))
+}
+
+// PipeMap adds a stage to the pipeline that transforms every item with f,
+// see Map
+func PipeMap[T, U any](p *Pipeline[T], f func(ctx context.Context, v T) (U, error)) *Pipeline[U] {
+	return then(p, "Map", func(ctx context.Context, in Sequence[T], opts []Option) Sequence[U] {
+		return Map(ctx, in, f, opts...)
+	})
+}
+
+// PipeBatch adds a stage to the pipeline that groups the items into slices of
+// up to n values, see Batch
+func PipeBatch[T any](p *Pipeline[T], n int) *Pipeline[[]T] {
+	return then(p, "Batch", func(ctx context.Context, in Sequence[T], opts []Option) Sequence[[]T] {
+		return Batch(ctx, in, n, opts...)
+	})
+}
+
+// then appends a stage of the given kind to the pipeline
+func then[T, U any](p *Pipeline[T], kind string, stage func(ctx context.Context, in Sequence[T], opts []Option) Sequence[U]) *Pipeline[U] {
+	name := fmt.Sprintf("%d.%s", len(p.stages)+1, kind)
+	if p.name != "" {
+		name = p.name + "." + name
+	}
+	opts := append(p.opts[:len(p.opts):len(p.opts)], WithName(name))
+	prev := p.build
+	return &Pipeline[U]{
+		name:   p.name,
+		opts:   p.opts,
+		stages: append(p.stages[:len(p.stages):len(p.stages)], name),
+		build: func(ctx context.Context) Sequence[U] {
+			return stage(ctx, prev(ctx), opts)
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package async
+
+import (
+	"cmp"
+	"context"
+	"reflect"
+	"slices"
+)
+
+// Prioritized is an input of MergeByPriority with its priority, higher
+// values are preferred.
+type Prioritized[T any] struct {
+	Seq      Sequence[T]
+	Priority int
+}
+
+// PriorityMerge forwards the items of both sequences on a single sequence and
+// always prefers an item of high that is ready over one of low. Items of low
+// are only forwarded while high has nothing ready, which is meant for mixing
+// urgent control events with bulk data in one consumer loop.
+//
+// low starves as long as high keeps producing items faster than the consumer
+// receives them, this is by design. Error items are forwarded as they are.
+//
+// The returned sequence is closed when both inputs are closed or the context
+// is done.
+//
+// Example:
+//
+//	for result := range PriorityMerge(ctx, controls, data) {
+//	    handle(result.Value)
+//	}
+func PriorityMerge[T any](ctx context.Context, high, low Sequence[T]) Sequence[T] {
+	return MergeByPriority(ctx, Prioritized[T]{Seq: high, Priority: 1}, Prioritized[T]{Seq: low})
+}
+
+// MergeByPriority is the variadic version of PriorityMerge: an input is only
+// forwarded from while all inputs with a higher priority have nothing ready.
+// Inputs with the same priority are preferred in the order they are passed.
+//
+// Example:
+//
+//	events := MergeByPriority(ctx,
+//	    Prioritized[Event]{Seq: alerts, Priority: 2},
+//	    Prioritized[Event]{Seq: updates, Priority: 1},
+//	    Prioritized[Event]{Seq: metrics},
+//	)
+func MergeByPriority[T any](ctx context.Context, ins ...Prioritized[T]) Sequence[T] {
+	ins = slices.Clone(ins)
+	slices.SortStableFunc(ins, func(a, b Prioritized[T]) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+	r := make(Sequence[T])
+	spawn("MergeByPriority", nil, func() {
+		defer close(r)
+		open := make([]Sequence[T], len(ins))
+		for i, in := range ins {
+			open[i] = in.Seq
+		}
+		for len(open) > 0 {
+			item, src, ok := pollByPriority(ctx, open)
+			if src < 0 {
+				return
+			}
+			if !ok {
+				open = slices.Delete(open, src, src+1)
+				continue
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+		}
+	})
+	return r
+}
+
+// pollByPriority receives the next item from the first input in order that
+// has one ready, or waits for any input if none has. It returns the index of
+// the input, which is negative if the context is done, and whether the input
+// delivered an item or was closed.
+func pollByPriority[T any](ctx context.Context, ins []Sequence[T]) (_Result[T], int, bool) {
+	for i, in := range ins {
+		select {
+		case item, ok := <-in:
+			return item, i, ok
+		default:
+		}
+	}
+	cases := make([]reflect.SelectCase, len(ins)+1)
+	for i, in := range ins {
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(in)}
+	}
+	cases[len(ins)] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	chosen, value, ok := reflect.Select(cases)
+	if chosen == len(ins) {
+		return _Result[T]{}, -1, false
+	}
+	if !ok {
+		return _Result[T]{}, chosen, false
+	}
+	return value.Interface().(_Result[T]), chosen, true
+}
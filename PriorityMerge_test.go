@@ -0,0 +1,64 @@
+package async_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// filled returns a closed sequence of n copies of v
+func filled(v string, n int) async.Sequence[string] {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = v
+	}
+	return seqOf(values...)
+}
+
+func TestPriorityMergePrefersHighUnderContention(t *testing.T) {
+	ctx := context.Background()
+	// both inputs are ready all the time, high has to win every time
+	values, _ := items(async.PriorityMerge(ctx, filled("high", 500), filled("low", 500)))
+	want := append(slices.Repeat([]string{"high"}, 500), slices.Repeat([]string{"low"}, 500)...)
+	if !slices.Equal(values, want) {
+		t.Fatalf("want all high items before the low ones, got %v", values)
+	}
+}
+
+func TestPriorityMergeForwardsLowWhileHighIsIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	high := make(async.Sequence[string])
+	merged := async.PriorityMerge(ctx, high, seqOf("a", "b"))
+	for _, want := range []string{"a", "b"} {
+		if item := <-merged; item.Value != want {
+			t.Fatalf("want %q from low while high is idle, got %v", want, item)
+		}
+	}
+	high <- async.Success("urgent")
+	if item := <-merged; item.Value != "urgent" {
+		t.Fatalf("want the high item, got %v", item)
+	}
+	// low is closed, high is still open
+	cancel()
+	asynctest.Drained(t, context.Background(), merged)
+}
+
+func TestMergeByPriority(t *testing.T) {
+	ctx := context.Background()
+	merged := async.MergeByPriority(ctx,
+		async.Prioritized[string]{Seq: filled("metrics", 100)},
+		async.Prioritized[string]{Seq: filled("alerts", 100), Priority: 2},
+		async.Prioritized[string]{Seq: filled("updates", 100), Priority: 1},
+	)
+	values, _ := items(merged)
+	var want []string
+	for _, v := range []string{"alerts", "updates", "metrics"} {
+		want = append(want, slices.Repeat([]string{v}, 100)...)
+	}
+	if !slices.Equal(values, want) {
+		t.Fatalf("want the items in priority order, got %v", values)
+	}
+}
@@ -0,0 +1,51 @@
+package async
+
+import (
+	"context"
+)
+
+// Limiter paces operations. It is satisfied by *rate.Limiter of the package
+// golang.org/x/time/rate, which provides token bucket semantics including
+// bursts, without this package depending on it.
+type Limiter interface {
+	// Wait blocks until the next operation is allowed or returns an error if
+	// that is not possible, e.g. because the context is done.
+	Wait(ctx context.Context) error
+}
+
+// RateLimit forwards every item of the input after waiting on the given
+// limiter, so the items are emitted at the pace the limiter allows.
+//
+// If waiting fails, e.g. because the wait would exceed the context deadline,
+// the error is emitted as a final error item and the returned sequence is
+// closed. The sequence is also closed when the input is closed or the context
+// is done.
+//
+// Stream can be paced the same way with WithRateLimit.
+//
+// Example:
+//
+//	limiter := rate.NewLimiter(rate.Every(100*time.Millisecond), 10)
+//	for result := range RateLimit(ctx, requests, limiter) {
+//	    callAPI(ctx, result.Value)
+//	}
+func RateLimit[T any](ctx context.Context, in Sequence[T], l Limiter) Sequence[T] {
+	r := make(Sequence[T])
+	spawn("RateLimit", nil, func() {
+		defer close(r)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if err := l.Wait(ctx); err != nil {
+				send(ctx, r, Fail[T](err))
+				return
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+		}
+	})
+	return r
+}
@@ -0,0 +1,185 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Replay records a sequence and replays it to subscribers, see ReplaySeq.
+type Replay[T any] struct {
+	limit    int
+	blocking bool
+
+	mu sync.Mutex
+	// items are the retained items, offset is the position of the first one
+	// in the recorded sequence
+	items  []_Result[T]
+	offset int
+	done   bool
+	subs   map[*replayCursor]struct{}
+	// changed is closed and replaced whenever the state changes
+	changed chan struct{}
+}
+
+// replayCursor is the position of a subscriber in the recorded sequence
+type replayCursor struct {
+	next int
+}
+
+// ReplaySeq consumes the input and records it, so that every subscriber
+// receives the last limit items, or all items if limit is zero or less,
+// followed by the live items. Every subscriber gets its own sequence and
+// consumes it at its own pace.
+//
+// A subscriber that falls behind by more than limit items misses the oldest
+// ones, it continues with the oldest item still retained. With
+// WithBlockOnSlowSubscriber the recording waits for the slowest subscriber
+// instead, so no subscriber misses an item, at the cost of slowing down the
+// input to the pace of that subscriber.
+//
+// The recording stops when the input is closed or the context is done. The
+// subscriptions are closed once they delivered everything recorded up to
+// that point. With a limit of zero or less the whole input is kept in memory.
+//
+// Supported options:
+//   - WithBlockOnSlowSubscriber
+//
+// Example:
+//
+//	prices := ReplaySeq(ctx, Stream(ctx, nextPrice), 100)
+//	for result := range prices.Subscribe(ctx) {
+//	    log.Printf("price: %v", result.Value)
+//	}
+func ReplaySeq[T any](ctx context.Context, in Sequence[T], limit int, opts ...Option) *Replay[T] {
+	o := newOptions(opts)
+	p := &Replay[T]{
+		limit:    limit,
+		blocking: o.blockOnSlow,
+		subs:     map[*replayCursor]struct{}{},
+		changed:  make(chan struct{}),
+	}
+	spawn("ReplaySeq", o, func() {
+		defer p.finish()
+		for {
+			item, ok := receive(ctx, in)
+			if !ok || !p.record(ctx, item) {
+				return
+			}
+		}
+	})
+	return p
+}
+
+// Subscribe returns a sequence with the retained items followed by the live
+// items of the recorded sequence. It is closed when the recording ended and
+// all items were delivered, or when the given context is done.
+func (p *Replay[T]) Subscribe(ctx context.Context) Sequence[T] {
+	p.mu.Lock()
+	c := &replayCursor{next: p.offset}
+	p.subs[c] = struct{}{}
+	p.mu.Unlock()
+	r := make(Sequence[T])
+	spawn("Replay.Subscribe", nil, func() {
+		defer close(r)
+		defer p.unsubscribe(c)
+		for {
+			item, changed, ok := p.next(c)
+			if changed != nil {
+				select {
+				case <-changed:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			if !ok || !send(ctx, r, item) {
+				return
+			}
+			p.advance(c)
+		}
+	})
+	return r
+}
+
+// next returns the next item for the cursor. If there is none yet, it returns
+// the channel to wait on, ok is false if the recording ended.
+func (p *Replay[T]) next(c *replayCursor) (item _Result[T], changed <-chan struct{}, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c.next = max(c.next, p.offset)
+	if i := c.next - p.offset; i < len(p.items) {
+		return p.items[i], nil, true
+	}
+	if p.done {
+		return item, nil, false
+	}
+	return item, p.changed, false
+}
+
+// advance moves the cursor past the item it delivered
+func (p *Replay[T]) advance(c *replayCursor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c.next++
+	if p.blocking {
+		p.notify()
+	}
+}
+
+func (p *Replay[T]) unsubscribe(c *replayCursor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.subs, c)
+	p.notify()
+}
+
+// record appends an item to the recording and drops the oldest one if the
+// limit is exceeded. It reports false if the context is done while waiting
+// for a slow subscriber.
+func (p *Replay[T]) record(ctx context.Context, item _Result[T]) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.items = append(p.items, item)
+	if p.limit > 0 && len(p.items) > p.limit {
+		for p.blocking && p.lagging() {
+			changed := p.changed
+			p.mu.Unlock()
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				p.mu.Lock()
+				return false
+			}
+			p.mu.Lock()
+		}
+		var zero _Result[T]
+		p.items[0] = zero
+		p.items = p.items[1:]
+		p.offset++
+	}
+	p.notify()
+	return true
+}
+
+// lagging reports whether a subscriber did not receive the oldest item yet
+func (p *Replay[T]) lagging() bool {
+	for c := range p.subs {
+		if c.next <= p.offset {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Replay[T]) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done = true
+	p.notify()
+}
+
+// notify wakes all goroutines waiting for a change, p.mu must be held
+func (p *Replay[T]) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
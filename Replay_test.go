@@ -0,0 +1,133 @@
+package async_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// record feeds the values 1 to n into a replay with the given limit, keeping
+// a subscriber in pace, and returns the replay once the recording ended
+func record(t *testing.T, ctx context.Context, n, limit int) *async.Replay[int] {
+	t.Helper()
+	in := make(async.Sequence[int])
+	p := async.ReplaySeq(ctx, in, limit)
+	pacer := p.Subscribe(ctx)
+	for i := 1; i <= n; i++ {
+		in <- async.Success(i)
+		if item := <-pacer; item.Value != i {
+			t.Fatalf("want the live item %d, got %v", i, item)
+		}
+	}
+	close(in)
+	asynctest.Drained(t, ctx, pacer)
+	return p
+}
+
+func TestReplayLateSubscriber(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	p := record(t, ctx, 10, 3)
+	asynctest.ExpectValues(t, ctx, p.Subscribe(ctx), []int{8, 9, 10})
+	// every subscriber gets the tail
+	asynctest.ExpectValues(t, ctx, p.Subscribe(ctx), []int{8, 9, 10})
+}
+
+func TestReplayUnlimited(t *testing.T) {
+	ctx := context.Background()
+	p := record(t, ctx, 10, 0)
+	asynctest.ExpectValues(t, ctx, p.Subscribe(ctx), []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+}
+
+func TestReplayLiveAfterTail(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(async.Sequence[int])
+	p := async.ReplaySeq(ctx, in, 2)
+	pacer := p.Subscribe(ctx)
+	for i := 1; i <= 4; i++ {
+		in <- async.Success(i)
+		<-pacer
+	}
+	late := p.Subscribe(ctx)
+	for _, want := range []int{3, 4} {
+		if item := <-late; item.Value != want {
+			t.Fatalf("want the retained item %d, got %v", want, item)
+		}
+	}
+	in <- async.Success(5)
+	if item := <-late; item.Value != 5 {
+		t.Fatalf("want the live item after the tail, got %v", item)
+	}
+}
+
+// sent reports whether the value is taken from in within d
+func sent(in async.Sequence[int], v int, d time.Duration) bool {
+	select {
+	case in <- async.Success(v):
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func TestReplayBlockOnSlowSubscriber(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	in := make(async.Sequence[int])
+	p := async.ReplaySeq(ctx, in, 3, async.WithBlockOnSlowSubscriber())
+	slow := p.Subscribe(ctx)
+
+	// the recording takes one item beyond the limit, then waits for the
+	// subscriber
+	for i := 1; i <= 4; i++ {
+		if !sent(in, i, 5*time.Second) {
+			t.Fatalf("want item %d recorded", i)
+		}
+	}
+	if sent(in, 5, 20*time.Millisecond) {
+		t.Fatal("want the recording blocked by the slow subscriber")
+	}
+	go func() {
+		for i := 5; i <= 10; i++ {
+			in <- async.Success(i)
+		}
+		close(in)
+	}()
+	values, _ := items(slow)
+	if !slices.Equal(values, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Fatalf("want the slow subscriber to miss no item, got %v", values)
+	}
+}
+
+func TestReplayCancelledSubscriber(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	in := make(async.Sequence[int])
+	p := async.ReplaySeq(ctx, in, 1, async.WithBlockOnSlowSubscriber())
+	subCtx, cancel := context.WithCancel(ctx)
+	slow := p.Subscribe(subCtx)
+	for i := 1; i <= 2; i++ {
+		if !sent(in, i, 5*time.Second) {
+			t.Fatalf("want item %d recorded", i)
+		}
+	}
+	if sent(in, 3, 20*time.Millisecond) {
+		t.Fatal("want the recording blocked by the slow subscriber")
+	}
+
+	// the cancelled subscriber no longer holds back the recording, without
+	// anybody reading its sequence
+	cancel()
+	for i := 3; i <= 10; i++ {
+		if !sent(in, i, 5*time.Second) {
+			t.Fatalf("want item %d recorded after the subscriber left", i)
+		}
+	}
+	close(in)
+	asynctest.Drained(t, ctx, slow)
+}
@@ -0,0 +1,89 @@
+package async
+
+import (
+	"context"
+)
+
+// Resubscribe consumes the sequence returned by start and, when it ends with
+// an error item, waits the delay returned by backoff and calls start again,
+// splicing the new sequence in transparently. This keeps streams backed by
+// flaky sources, such as websockets or change feeds, running.
+//
+// The error item an inner sequence ended with is not forwarded if it is
+// restarted, the other items are forwarded as they are. The wrapper ends for
+// good when an inner sequence is closed after a successful item or without
+// any item, or when maxRestarts restarts were used up, then the last error is
+// emitted. A nil backoff restarts immediately.
+//
+// With WithHealthyAfter the count of restarts is reset once an inner
+// sequence ran for the given duration, so only failures in quick succession
+// exhaust maxRestarts.
+//
+// Every inner sequence gets its own context derived from ctx, which is
+// cancelled once the sequence ended. The returned sequence is closed when the
+// wrapper ended or the context is done.
+//
+// Supported options:
+//   - WithClock
+//   - WithHealthyAfter
+//
+// Example:
+//
+//	events := Resubscribe(ctx, 10, ExponentialBackoff(time.Second, time.Minute), func(ctx context.Context) Sequence[Event] {
+//	    return feed.Subscribe(ctx)
+//	}, WithHealthyAfter(5*time.Minute))
+func Resubscribe[T any](ctx context.Context, maxRestarts int, backoff BackoffFunc, start func(ctx context.Context) Sequence[T], opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Resubscribe", o, func() {
+		defer close(r)
+		for restarts := 0; ; {
+			started := o.clock.Now()
+			last, ok := runSubscription(ctx, r, start)
+			if !ok || last == nil {
+				return
+			}
+			if o.healthyAfter > 0 && o.clock.Now().Sub(started) >= o.healthyAfter {
+				restarts = 0
+			}
+			if restarts >= maxRestarts {
+				send(ctx, r, Fail[T](last))
+				return
+			}
+			restarts++
+			if backoff != nil && o.clock.Sleep(ctx, backoff(restarts)) != nil {
+				return
+			}
+		}
+	})
+	return r
+}
+
+// runSubscription forwards the items of a single inner sequence. It returns
+// the error of the final item, which is held back, and false if forwarding
+// failed because the context is done.
+func runSubscription[T any](ctx context.Context, r Sequence[T], start func(ctx context.Context) Sequence[T]) (error, bool) {
+	ictx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	in := start(ictx)
+	var held error
+	for {
+		item, ok := receive(ctx, in)
+		if !ok {
+			return held, ctx.Err() == nil
+		}
+		if held != nil {
+			if !send(ctx, r, Fail[T](held)) {
+				return nil, false
+			}
+			held = nil
+		}
+		if item.Error != nil {
+			held = item.Error
+			continue
+		}
+		if !send(ctx, r, item) {
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// subscriptions is a flaky source whose subscriptions emit one value each
+// and then fail, until the given number of failures is used up
+type subscriptions struct {
+	mu       sync.Mutex
+	failures int
+	started  int
+	ctxs     []context.Context
+}
+
+func (s *subscriptions) start(ctx context.Context) async.Sequence[int] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started++
+	s.ctxs = append(s.ctxs, ctx)
+	seq := make(async.Sequence[int], 2)
+	seq <- async.Success(s.started)
+	if s.started <= s.failures {
+		seq <- async.Fail[int](errDownstream)
+	}
+	close(seq)
+	return seq
+}
+
+// recordedBackoff waits one second per attempt and records the attempts
+func recordedBackoff(attempts *[]int) async.BackoffFunc {
+	return func(attempt int) time.Duration {
+		*attempts = append(*attempts, attempt)
+		return time.Duration(attempt) * time.Second
+	}
+}
+
+func TestResubscribeAfterFailures(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &subscriptions{failures: 2}
+	var attempts []int
+	seq := async.Resubscribe(ctx, 5, recordedBackoff(&attempts), source.start, async.WithClock(clock))
+
+	for restart := 1; restart <= 2; restart++ {
+		if item := <-seq; item.Value != restart || item.Error != nil {
+			t.Fatalf("want the value of subscription %d, got %v", restart, item)
+		}
+		// the restart waits for the backoff
+		clock.BlockUntil(1)
+		clock.Advance(time.Duration(restart)*time.Second - time.Nanosecond)
+		select {
+		case item := <-seq:
+			t.Fatalf("want no item before the backoff elapsed, got %v", item)
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(time.Nanosecond)
+	}
+	values, errs := items(seq)
+	if !slices.Equal(values, []int{3}) || len(errs) != 0 {
+		t.Fatalf("want the last subscription spliced in without errors, got %v and %v", values, errs)
+	}
+	if !slices.Equal(attempts, []int{1, 2}) {
+		t.Fatalf("want a backoff per restart, got attempts %v", attempts)
+	}
+	for i, ctx := range source.ctxs {
+		if ctx.Err() == nil {
+			t.Fatalf("want the context of subscription %d cancelled once it ended", i+1)
+		}
+	}
+}
+
+func TestResubscribeMaxRestarts(t *testing.T) {
+	ctx := context.Background()
+	source := &subscriptions{failures: 10}
+	values, errs := items(async.Resubscribe(ctx, 3, nil, source.start))
+	if !slices.Equal(values, []int{1, 2, 3, 4}) {
+		t.Fatalf("want the values of the first subscription and 3 restarts, got %v", values)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the last error once the restarts were used up, got %v", errs)
+	}
+}
+
+func TestResubscribeHealthyAfter(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &subscriptions{failures: 3}
+	seq := async.Resubscribe(ctx, 1, nil, func(ctx context.Context) async.Sequence[int] {
+		// every subscription runs long enough to count as healthy
+		clock.Advance(time.Minute)
+		return source.start(ctx)
+	}, async.WithClock(clock), async.WithHealthyAfter(time.Minute))
+	values, errs := items(seq)
+	if !slices.Equal(values, []int{1, 2, 3, 4}) || len(errs) != 0 {
+		t.Fatalf("want the restarts reset after healthy subscriptions, got %v and %v", values, errs)
+	}
+}
+
+func TestResubscribeCancelledDuringBackoff(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := fakeclock.New(time.Unix(0, 0))
+	source := &subscriptions{failures: 10}
+	var attempts []int
+	seq := async.Resubscribe(ctx, 5, recordedBackoff(&attempts), source.start, async.WithClock(clock))
+	<-seq
+	clock.BlockUntil(1)
+	cancel()
+	asynctest.Drained(t, context.Background(), seq)
+	if source.started != 1 {
+		t.Fatalf("want no restart after the cancellation, got %d subscriptions", source.started)
+	}
+}
@@ -1,5 +1,7 @@
 package async
 
+import "sync"
+
 type _Result[T any] struct {
 	Value T
 	Error error
@@ -19,8 +21,33 @@ func Success[T any](val T) _Result[T] {
 // This generates an error async result
 // - Anyway this function it not necessary when using Exec() or Stream
 func Fail[T any](err error) _Result[T] {
+	var zero T
 	return _Result[T]{
-		Value: *new(T),
+		Value: zero,
 		Error: err,
 	}
 }
+
+// resolved returns a Result that already holds the given item and is closed
+func resolved[T any](item _Result[T]) Result[T] {
+	r := make(Result[T], 1)
+	r <- item
+	close(r)
+	return r
+}
+
+// waiter is a Result shared by a single caller, which is resolved either with
+// a shared outcome or with the error of the context of the caller, whichever
+// comes first. stop unregisters the context watch.
+type waiter[T any] struct {
+	once sync.Once
+	r    Result[T]
+	stop func() bool
+}
+
+func (w *waiter[T]) resolve(item _Result[T]) {
+	w.once.Do(func() {
+		w.r <- item
+		close(w.r)
+	})
+}
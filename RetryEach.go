@@ -0,0 +1,97 @@
+package async
+
+import (
+	"context"
+	"fmt"
+)
+
+// RetryError is emitted by RetryEach when all attempts to transform an item
+// failed. It records the number of attempts and the error of the last one.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("async: failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// RetryEach transforms every item of the input with the given function,
+// retrying every item independently up to the given number of attempts.
+//
+// As soon as an attempt succeeds, its value is emitted. If all attempts fail,
+// an error item with a *RetryError, wrapped into an *IndexedError, is
+// emitted and the stage continues with the next item. Between two attempts,
+// the delay returned by backoff is waited. A nil backoff retries immediately,
+// and attempts smaller than 1 are treated as 1.
+//
+// The stage is strictly sequential: an item is only read from the input after
+// the previous one succeeded or exhausted its attempts, so a backoff delays
+// the following items as well. Use it together with a concurrent stage if the
+// items should be retried in parallel. Waiting is aborted as soon as the
+// context is done.
+//
+// Error items of the input are forwarded wrapped with the index of the item
+// without being retried. Every item of the input advances the index.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// If the stage is named with WithName, the *RetryError is wrapped into an
+// *AsyncError that records the number of the last attempt.
+//
+// Supported options:
+//   - WithClock
+//   - WithName
+//
+// Example:
+//
+//	users := RetryEach(ctx, ids, 3, ExponentialBackoff(100*time.Millisecond, time.Second), fetchUser)
+func RetryEach[T, U any](ctx context.Context, in Sequence[T], attempts int, backoff BackoffFunc, f func(ctx context.Context, v T) (U, error), opts ...Option) Sequence[U] {
+	o := newOptions(opts)
+	attempts = max(attempts, 1)
+	r := make(Sequence[U])
+	spawn("RetryEach", o, func() {
+		defer close(r)
+		for index := 0; ; index++ {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				if !send(ctx, r, Fail[U](indexInputError(index, item.Error))) {
+					return
+				}
+				continue
+			}
+			var (
+				value U
+				err   error
+			)
+			for attempt := 1; ; attempt++ {
+				if value, err = f(ctx, item.Value); err == nil {
+					break
+				}
+				if attempt == attempts {
+					err = asyncError(context.Background(), o.name, attempt, &RetryError{Attempts: attempt, Err: err})
+					break
+				}
+				if backoff != nil && o.clock.Sleep(ctx, backoff(attempt)) != nil {
+					return
+				}
+			}
+			out := Success(value)
+			if err != nil {
+				out = Fail[U](indexError(index, err))
+			}
+			if !send(ctx, r, out) {
+				return
+			}
+		}
+	})
+	return r
+}
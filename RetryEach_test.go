@@ -0,0 +1,79 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// flaky fails the first failures calls for every value
+func flaky(failures int) (func(ctx context.Context, v int) (int, error), map[int]int) {
+	calls := map[int]int{}
+	return func(ctx context.Context, v int) (int, error) {
+		calls[v]++
+		if calls[v] <= failures {
+			return 0, errDownstream
+		}
+		return v * 10, nil
+	}, calls
+}
+
+func TestRetryEachWaitsForBackoff(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	f, calls := flaky(2)
+	out := async.RetryEach(ctx, seqOf(1), 3, async.ExponentialBackoff(time.Second, time.Minute), f, async.WithClock(clock))
+
+	clock.BlockUntil(1)
+	clock.Advance(999 * time.Millisecond)
+	if clock.Waiters() != 1 {
+		t.Fatal("want the first backoff still pending")
+	}
+	clock.Advance(time.Millisecond)
+	// the second backoff is doubled
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Second)
+	asynctest.ExpectValues(t, ctx, out, []int{10})
+	if calls[1] != 3 {
+		t.Fatalf("want 3 attempts, got %d", calls[1])
+	}
+}
+
+func TestRetryEachExhaustsAttempts(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	f, _ := flaky(5)
+	out := async.RetryEach(ctx, seqOf(1), 2, async.ConstantBackoff(time.Second), f, async.WithClock(clock))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	item := <-out
+	var retryErr *async.RetryError
+	if !errors.As(item.Error, &retryErr) || retryErr.Attempts != 2 || !errors.Is(item.Error, errDownstream) {
+		t.Fatalf("want a RetryError after 2 attempts, got %v", item.Error)
+	}
+	var indexed *async.IndexedError
+	if !errors.As(item.Error, &indexed) || indexed.Index != 0 {
+		t.Fatalf("want the error wrapped with the index 0, got %v", item.Error)
+	}
+	asynctest.Drained(t, ctx, out)
+}
+
+func TestRetryEachCancelledDuringBackoff(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	f, calls := flaky(5)
+	ctx, cancel := context.WithCancel(context.Background())
+	out := async.RetryEach(ctx, seqOf(1), 3, async.ConstantBackoff(time.Hour), f, async.WithClock(clock))
+
+	clock.BlockUntil(1)
+	cancel()
+	asynctest.Drained(t, context.Background(), out)
+	if calls[1] != 1 {
+		t.Fatalf("want no attempt after the cancellation, got %d", calls[1])
+	}
+}
@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Rows streams the rows of a database query, converting every row with scan,
+// so that database cursors compose with the stages of this package.
+//
+// An error of scan is emitted as error item and the iteration continues with
+// the next row. After the last row, rows.Err() is checked and emitted as
+// final error item if it is not nil.
+//
+// rows.Close() is always called when the sequence ends, whether the rows were
+// exhausted, the context is done or the consumer went away and the context
+// was cancelled. The query should be run with the same context, so that the
+// driver aborts a pending call of rows.Next as well.
+//
+// The returned sequence is closed when the rows are exhausted or the context
+// is done.
+//
+// Supported options:
+//   - WithName
+//   - WithRunner
+//
+// Example:
+//
+//	rows, err := db.QueryContext(ctx, "SELECT id, name FROM users")
+//	if err != nil {
+//	    return err
+//	}
+//	users := Rows(ctx, rows, func(rows *sql.Rows) (User, error) {
+//	    var u User
+//	    err := rows.Scan(&u.ID, &u.Name)
+//	    return u, err
+//	})
+func Rows[T any](ctx context.Context, rows *sql.Rows, scan func(rows *sql.Rows) (T, error), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Rows", o, func() {
+		defer close(r)
+		defer rows.Close()
+		for ctx.Err() == nil && rows.Next() {
+			value, err := scan(rows)
+			item := Success(value)
+			if err != nil {
+				item = Fail[T](err)
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			send(ctx, r, Fail[T](err))
+		}
+	})
+	return r
+}
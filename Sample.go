@@ -0,0 +1,66 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Sample emits, on every tick of the given interval, the most recent
+// successful item received since the previous tick. If no item arrived in the
+// meantime, nothing is emitted for that tick.
+//
+// Only the latest item is retained, so the memory usage is constant
+// regardless of the input rate. Error items are not sampled, they are
+// forwarded immediately.
+//
+// When the input is closed, the latest item not emitted yet is flushed before
+// the returned sequence is closed. The ticker is stopped when the stage ends.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	for result := range Sample(ctx, prices, time.Second) {
+//	    render(result.Value)
+//	}
+func Sample[T any](ctx context.Context, in Sequence[T], every time.Duration, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Sample", o, func() {
+		defer close(r)
+		ticker := o.clock.NewTicker(every)
+		defer ticker.Stop()
+		var latest _Result[T]
+		hasLatest := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				if !hasLatest {
+					continue
+				}
+				hasLatest = false
+				if !send(ctx, r, latest) {
+					return
+				}
+			case item, ok := <-in:
+				if !ok {
+					if hasLatest {
+						send(ctx, r, latest)
+					}
+					return
+				}
+				if item.Error != nil {
+					if !send(ctx, r, item) {
+						return
+					}
+					continue
+				}
+				latest, hasLatest = item, true
+			}
+		}
+	})
+	return r
+}
@@ -0,0 +1,44 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+func TestSampleEmitsLatestPerTick(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	out := async.Sample(ctx, in, time.Second, async.WithClock(clock))
+	clock.BlockUntil(1)
+
+	in <- async.Success(1)
+	in <- async.Success(2)
+	processed(t, in, out)
+	clock.Advance(time.Second)
+	if item := <-out; item.Value != 2 {
+		t.Fatalf("want the latest item on the tick, got %v", item)
+	}
+
+	// a tick without a new item emits nothing
+	clock.Advance(time.Second)
+	close(in)
+	asynctest.ExpectValues(t, ctx, out, []int{})
+}
+
+func TestSampleFlushesOnClose(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	out := async.Sample(ctx, in, time.Second, async.WithClock(clock))
+	in <- async.Success(1)
+	in <- async.Success(2)
+	close(in)
+	asynctest.ExpectValues(t, ctx, out, []int{2})
+}
@@ -0,0 +1,84 @@
+package async
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// Lines streams the lines of the reader, without the line endings. It is Scan
+// with bufio.ScanLines, see there for the details.
+//
+// Supported options:
+//   - WithName
+//   - WithRunner
+//
+// Example:
+//
+//	f, err := os.Open("access.log")
+//	if err != nil {
+//	    return err
+//	}
+//	for result := range Lines(ctx, f) {
+//	    log.Print(result.Value)
+//	}
+func Lines(ctx context.Context, r io.Reader, opts ...Option) Sequence[string] {
+	return Scan(ctx, r, bufio.ScanLines, func(b []byte) (string, error) {
+		return string(b), nil
+	}, opts...)
+}
+
+// Scan reads the reader in a goroutine, splits it into records with split and
+// emits every record converted with parse. It is the bridge for processing
+// large files with the stages of this package.
+//
+// The slice passed to parse is only valid during the call, parse must copy
+// what it retains. An error of parse is emitted as error item and scanning
+// continues with the next record. A read error, including a record that
+// exceeds the maximum token size of bufio.Scanner, is emitted as final error
+// item. The sequence ends at io.EOF.
+//
+// The context is checked between the records, so cancellation stops reading
+// at the next record boundary. If the reader implements io.Closer, it is
+// closed when the sequence ends.
+//
+// The returned sequence is closed when the reader is exhausted, a read error
+// occurred or the context is done.
+//
+// Supported options:
+//   - WithName
+//   - WithRunner
+//
+// Example:
+//
+//	records := Scan(ctx, f, bufio.ScanLines, func(b []byte) (Record, error) {
+//	    var r Record
+//	    err := json.Unmarshal(b, &r)
+//	    return r, err
+//	})
+func Scan[T any](ctx context.Context, r io.Reader, split bufio.SplitFunc, parse func(b []byte) (T, error), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	out := make(Sequence[T])
+	spawn("Scan", o, func() {
+		defer close(out)
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Split(split)
+		for ctx.Err() == nil && scanner.Scan() {
+			value, err := parse(scanner.Bytes())
+			item := Success(value)
+			if err != nil {
+				item = Fail[T](err)
+			}
+			if !send(ctx, out, item) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			send(ctx, out, Fail[T](err))
+		}
+	})
+	return out
+}
@@ -1,5 +1,38 @@
 package async
 
+import (
+	"context"
+)
+
 // Sequence is technically the same as Result, but it has other semantics
 // - A Result is meant to return a single result while a Sequence is meant to return multiple
 type Sequence[T any] Result[T]
+
+// send delivers the given item on the sequence unless the context is done.
+// It reports whether the item was delivered. A context that is already done
+// always wins, even if a consumer is ready to receive.
+func send[T any](ctx context.Context, s Sequence[T], item _Result[T]) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case s <- item:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// receive waits for the next item of the sequence. It reports false if the
+// sequence was closed or the context is done.
+func receive[T any](ctx context.Context, s Sequence[T]) (_Result[T], bool) {
+	if ctx.Err() != nil {
+		return _Result[T]{}, false
+	}
+	select {
+	case item, ok := <-s:
+		return item, ok
+	case <-ctx.Done():
+		return _Result[T]{}, false
+	}
+}
@@ -0,0 +1,118 @@
+package async
+
+import (
+	"context"
+	"slices"
+)
+
+// Sorted collects all successful values of the input, sorts them by less and
+// resolves with the sorted slice once the input is closed. The sort is
+// stable, so equal values keep the order of the input.
+//
+// The first error item fails the Result immediately and the rest of the input
+// is abandoned, unless WithSkipErrors is set, which drops error items. If the
+// context is done before the input is closed, the Result fails with
+// ctx.Err().
+//
+// Everything is buffered in memory until the input is closed, so Sorted is
+// only meant for inputs of bounded size. If the size is known approximately,
+// WithSizeHint preallocates the buffer.
+//
+// Supported options:
+//   - WithSizeHint
+//   - WithSkipErrors
+//
+// Example:
+//
+//	r := <-Sorted(ctx, users, func(a, b User) bool {
+//	    return a.Name < b.Name
+//	}, WithSizeHint(1000))
+//	if r.Error != nil {
+//	    return r.Error
+//	}
+func Sorted[T any](ctx context.Context, in Sequence[T], less func(a, b T) bool, opts ...Option) Result[[]T] {
+	o := newOptions(opts)
+	r := make(Result[[]T], 1)
+	spawn("Sorted", o, func() {
+		defer close(r)
+		values, err := collectSorted(ctx, in, less, o)
+		if err != nil {
+			r <- Fail[[]T](err)
+		} else {
+			r <- Success(values)
+		}
+	})
+	return r
+}
+
+// SortedSeq is the streaming variant of Sorted: it collects and sorts the
+// values of the input in the same way and emits them one by one once the
+// input is closed. If collecting fails, the error is emitted as single item.
+//
+// The returned sequence is closed when all values were emitted or the context
+// is done.
+//
+// Supported options:
+//   - WithSizeHint
+//   - WithSkipErrors
+//
+// Example:
+//
+//	for result := range SortedSeq(ctx, scores, func(a, b int) bool { return a > b }) {
+//	    log.Printf("score: %v", result.Value)
+//	}
+func SortedSeq[T any](ctx context.Context, in Sequence[T], less func(a, b T) bool, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("SortedSeq", o, func() {
+		defer close(r)
+		values, err := collectSorted(ctx, in, less, o)
+		if err != nil {
+			send(ctx, r, Fail[T](err))
+			return
+		}
+		for _, v := range values {
+			if !send(ctx, r, Success(v)) {
+				return
+			}
+		}
+	})
+	return r
+}
+
+// collectSorted collects the values of the input and sorts them. It fails
+// with the error of the context only if the context is done before the input
+// is closed.
+func collectSorted[T any](ctx context.Context, in Sequence[T], less func(a, b T) bool, o *options) ([]T, error) {
+	values := make([]T, 0, max(o.sizeHint, 0))
+	for {
+		var item _Result[T]
+		var ok bool
+		select {
+		case item, ok = <-in:
+		case <-ctx.Done():
+			return nil, ctxError(ctx)
+		}
+		if !ok {
+			break
+		}
+		if item.Error != nil {
+			if o.skipErrors {
+				continue
+			}
+			return nil, item.Error
+		}
+		values = append(values, item.Value)
+	}
+	slices.SortStableFunc(values, func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		default:
+			return 0
+		}
+	})
+	return values, nil
+}
@@ -0,0 +1,37 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestSortedCancelledAfterInput(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the context is cancelled while sorting, after the input was closed
+	r := <-async.Sorted(ctx, seqOf(3, 1, 2), func(a, b int) bool {
+		cancel()
+		return a < b
+	})
+	if r.Error != nil || !slices.Equal(r.Value, []int{1, 2, 3}) {
+		t.Fatalf("want the sorted values of the closed input, got %v", r)
+	}
+}
+
+func TestSortedCancelledBeforeInput(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(async.Sequence[int], 1)
+	defer close(in)
+	in <- async.Success(1)
+	r := async.Sorted(ctx, in, func(a, b int) bool {
+		return a < b
+	})
+	cancel()
+	if item := <-r; !errors.Is(item.Error, context.Canceled) {
+		t.Fatalf("want the error of the context, got %v", item)
+	}
+}
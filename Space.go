@@ -0,0 +1,51 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Space forwards every item of the input, but delays the items so that at
+// least gap elapses between two emitted items. In contrast to Throttle no item
+// is dropped.
+//
+// While the stage waits, it does not read from the input, which propagates
+// backpressure to the producer. A wait is aborted as soon as the context is
+// done.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	for result := range Space(ctx, requests, 200*time.Millisecond) {
+//	    callRateLimitedAPI(ctx, result.Value)
+//	}
+func Space[T any](ctx context.Context, in Sequence[T], gap time.Duration, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Space", o, func() {
+		defer close(r)
+		var last time.Time
+		emitted := false
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if emitted {
+				if o.clock.Sleep(ctx, gap-o.clock.Now().Sub(last)) != nil {
+					return
+				}
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+			last, emitted = o.clock.Now(), true
+		}
+	})
+	return r
+}
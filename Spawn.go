@@ -0,0 +1,118 @@
+package async
+
+import (
+	"cmp"
+	"sync/atomic"
+
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// states of the goroutines recorded in the leak registry
+const (
+	stateRunning = "running"
+	stateSending = "blocked on send"
+)
+
+// Runner starts the goroutines of the package. The default implementation
+// uses the go statement. A custom one can be set with SetRunner or WithRunner,
+// e.g. to run the goroutines under a test harness.
+type Runner interface {
+	// Go executes fn, usually in a new goroutine
+	Go(fn func())
+}
+
+type goRunner struct{}
+
+func (goRunner) Go(fn func()) {
+	go fn()
+}
+
+type runnerHolder struct {
+	r Runner
+}
+
+var defaultRunner atomic.Pointer[runnerHolder]
+
+// SetRunner sets the runner for all goroutines of the package that have no
+// runner given with WithRunner. Passing nil restores the default runner.
+func SetRunner(r Runner) {
+	defer resetDefaultOptions()
+	if r == nil {
+		defaultRunner.Store(nil)
+		return
+	}
+	defaultRunner.Store(&runnerHolder{r: r})
+}
+
+// currentRunner returns the runner registered with SetRunner or the default
+func currentRunner() Runner {
+	if h := defaultRunner.Load(); h != nil {
+		return h.r
+	}
+	return goRunner{}
+}
+
+// spawn starts fn with the runner of the given options. kind is the function
+// starting the goroutine, which together with the task name identifies the
+// goroutine when leak verification is active. The options may be nil.
+func spawn(kind string, o *options, fn func()) {
+	runner, name := spawnParams(o)
+	e := registry.Register(kind, name, 1)
+	if e == nil {
+		runner.Go(fn)
+		return
+	}
+	runner.Go(func() {
+		defer e.Done()
+		fn()
+	})
+}
+
+// spawnTracked is like spawn, but passes the registry entry of the goroutine
+// to fn, so that it can record its state. The entry is nil if leak
+// verification is not active, which is safe to use.
+func spawnTracked(kind string, o *options, fn func(e *registry.Entry)) {
+	runner, name := spawnParams(o)
+	e := registry.Register(kind, name, 1)
+	if o.tracker == nil {
+		runner.Go(func() {
+			defer e.Done()
+			fn(e)
+		})
+		return
+	}
+	id := o.tracker.add(cmp.Or(name, kind))
+	runner.Go(func() {
+		defer o.tracker.remove(id)
+		defer e.Done()
+		fn(e)
+	})
+}
+
+// spawnEntry is like spawnTracked for goroutines whose state has a field for
+// the registry entry: the entry is stored in *e before fn is started. Without
+// leak verification and tracker, fn is passed to the runner as it is, which
+// saves the allocation of a wrapping closure.
+func spawnEntry(kind string, o *options, e **registry.Entry, fn func()) {
+	runner, name := spawnParams(o)
+	entry := registry.Register(kind, name, 1)
+	*e = entry
+	if entry == nil && o.tracker == nil {
+		runner.Go(fn)
+		return
+	}
+	id := o.tracker.add(cmp.Or(name, kind))
+	runner.Go(func() {
+		defer o.tracker.remove(id)
+		defer entry.Done()
+		fn()
+	})
+}
+
+// spawnParams returns the runner and the task name of the given options
+func spawnParams(o *options) (Runner, string) {
+	if o == nil {
+		return currentRunner(), ""
+	}
+	return o.runner, o.name
+}
@@ -0,0 +1,82 @@
+package async
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// maxStackDepth is the number of frames recorded for a StackError
+const maxStackDepth = 16
+
+// StackError wraps the errors delivered by a task started with
+// WithErrorStacks. It records where the task was started, so that an error
+// surfacing from a deeply composed pipeline can be traced back to the Do or
+// Stream call that produced it.
+//
+// The message is the one of the wrapped error, errors.Unwrap returns it.
+// Formatting with %+v adds the name of the task and the frames of the call
+// site.
+type StackError struct {
+	// Err is the original error
+	Err error
+	// Name is the name of the task given with WithName
+	Name string
+	pcs  []uintptr
+}
+
+func (e *StackError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StackError) Unwrap() error {
+	return e.Err
+}
+
+// Frames returns the call site of the task, starting with the innermost frame
+func (e *StackError) Frames() *runtime.Frames {
+	return runtime.CallersFrames(e.pcs)
+}
+
+// Format implements fmt.Formatter, %+v prints the call site of the task
+func (e *StackError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		fmt.Fprintf(s, "%+v", e.Err)
+		if e.Name != "" {
+			fmt.Fprintf(s, "\ntask %q started at:", e.Name)
+		} else {
+			io.WriteString(s, "\ntask started at:")
+		}
+		frames := e.Frames()
+		for {
+			f, more := frames.Next()
+			fmt.Fprintf(s, "\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+			if !more {
+				return
+			}
+		}
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// callSite records the caller of the function calling callSite if
+// WithErrorStacks is set, otherwise it returns nil
+func callSite(o *options) []uintptr {
+	if !o.errorStacks {
+		return nil
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// stackError wraps err into a *StackError if a call site was recorded
+func stackError(site []uintptr, name string, err error) error {
+	if site == nil || err == nil {
+		return err
+	}
+	return &StackError{Err: err, Name: name, pcs: site}
+}
@@ -0,0 +1,77 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// expectSite fails unless err is a *StackError whose innermost frame is the
+// given line of the calling function
+func expectSite(t *testing.T, err error, line int) *async.StackError {
+	t.Helper()
+	var serr *async.StackError
+	if !errors.As(err, &serr) {
+		t.Fatalf("want a *StackError, got %#v", err)
+	}
+	caller, _, _, _ := runtime.Caller(1)
+	f, _ := serr.Frames().Next()
+	if f.Function != runtime.FuncForPC(caller).Name() || f.Line != line {
+		t.Fatalf("want the call site at line %d of the test, got %s:%d", line, f.Function, f.Line)
+	}
+	return serr
+}
+
+func TestStackErrorDo(t *testing.T) {
+	errFailed := errors.New("failed")
+	_, _, line, _ := runtime.Caller(0)
+	r := async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errFailed
+	}, async.WithErrorStacks(), async.WithName("fetch"))
+	err := (<-r).Error
+	serr := expectSite(t, err, line+1)
+	if !errors.Is(err, errFailed) || err.Error() != serr.Err.Error() || serr.Name != "fetch" {
+		t.Fatalf("want the wrapped error of the named task, got %#v", serr)
+	}
+	formatted := fmt.Sprintf("%+v", err)
+	if want := "task \"fetch\" started at:\ngithub.com/uoul/go-async_test.TestStackErrorDo\n"; !strings.Contains(formatted, want) {
+		t.Fatalf("want %q in %s", want, formatted)
+	}
+	if want := fmt.Sprintf("StackError_test.go:%d", line+1); !strings.Contains(formatted, want) {
+		t.Fatalf("want %q in %s", want, formatted)
+	}
+}
+
+func TestStackErrorStream(t *testing.T) {
+	errFailed := errors.New("failed")
+	_, _, line, _ := runtime.Caller(0)
+	seq := async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		return 0, errFailed, false
+	}, async.WithErrorStacks())
+	_, errs := items(seq)
+	if len(errs) != 1 || !errors.Is(errs[0], errFailed) {
+		t.Fatalf("want the error of the step, got %v", errs)
+	}
+	expectSite(t, errs[0], line+1)
+}
+
+func TestStackErrorDisabled(t *testing.T) {
+	errFailed := errors.New("failed")
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errFailed
+	})
+	if r.Error != errFailed {
+		t.Fatalf("want the error unwrapped without WithErrorStacks, got %#v", r.Error)
+	}
+	ok := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		return 1, nil
+	}, async.WithErrorStacks())
+	if ok.Error != nil {
+		t.Fatalf("want no error for a success, got %v", ok.Error)
+	}
+}
@@ -0,0 +1,154 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// StageFunc is a step of a pipeline that consumes a sequence and produces
+// another one, see Stage and RunPipeline.
+type StageFunc[T, U any] func(ctx context.Context, in Sequence[T]) Sequence[U]
+
+// Stage creates a pipeline step that transforms the items of its input with
+// f on the given number of workers. Each stage has its own worker count and
+// its own bounded buffer towards the next stage, so a CPU heavy stage can run
+// 8 wide while an I/O bound stage runs 64 wide.
+//
+// The workers take the items from the input as they become free, so the
+// output is not in input order. An error returned by f is emitted as error
+// item, error items of the input are forwarded. Both are wrapped into an
+// *IndexedError with the position of the item in the input, which is the
+// way to relate an error to its item in the unordered output.
+//
+// No goroutine is started until the returned StageFunc is invoked. The output
+// of the stage is closed exactly once, after all its workers returned: when
+// the input is closed and processed, or when the context is done. Items in the
+// buffer of a stage whose context is done are abandoned.
+//
+// Every invocation of f runs with the instrumentation of Do under the name of
+// the stage. With a metrics receiver registered, every worker is reported as
+// a task and every emitted item is counted under the name, so the throughput
+// and the number of busy workers of each stage become visible.
+//
+// Supported options:
+//   - WithBuffer
+//   - WithInterceptors
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRecover
+//   - WithRunner
+//
+// Example:
+//
+//	resize := Stage("resize", runtime.NumCPU(), func(ctx context.Context, img Image) (Image, error) {
+//	    return img.Resize(800, 600), nil
+//	})
+//	upload := Stage("upload", 64, func(ctx context.Context, img Image) (string, error) {
+//	    return bucket.Put(ctx, img)
+//	}, WithBuffer(256))
+//	for result := range RunPipeline(ctx, images, Chain(resize, upload)) {
+//	    log.Printf("uploaded: %v", result.Value)
+//	}
+func Stage[T, U any](name string, workers int, f func(ctx context.Context, v T) (U, error), opts ...Option) StageFunc[T, U] {
+	o := newOptions(append(opts[:len(opts):len(opts)], WithName(name)))
+	workers = max(workers, 1)
+	buffer := o.buffer
+	if buffer <= 0 {
+		buffer = workers
+	}
+	return func(ctx context.Context, in Sequence[T]) Sequence[U] {
+		r := make(Sequence[U], buffer)
+		input := &stageInput[T]{in: in}
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for range workers {
+			spawn("Stage", o, func() {
+				defer wg.Done()
+				runStageWorker(ctx, o, input, r, f)
+			})
+		}
+		spawn("Stage.close", o, func() {
+			wg.Wait()
+			close(r)
+		})
+		return r
+	}
+}
+
+// stageInput is the input shared by the workers of a stage, which numbers
+// the items in the order they are received
+type stageInput[T any] struct {
+	mu   sync.Mutex
+	in   Sequence[T]
+	next int
+}
+
+// receive takes the next item of the input together with its index
+func (s *stageInput[T]) receive(ctx context.Context) (_Result[T], int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := receive(ctx, s.in)
+	if !ok {
+		return item, 0, false
+	}
+	index := s.next
+	s.next++
+	return item, index, true
+}
+
+// runStageWorker processes items of the input until it is closed or the
+// context is done
+func runStageWorker[T, U any](ctx context.Context, o *options, in *stageInput[T], out Sequence[U], f func(ctx context.Context, v T) (U, error)) {
+	m := currentMetrics()
+	if m != nil {
+		m.TaskStarted(o.name)
+		start := o.clock.Now()
+		defer func() {
+			m.TaskFinished(o.name, o.clock.Now().Sub(start), ctx.Err())
+		}()
+	}
+	for {
+		item, index, ok := in.receive(ctx)
+		if !ok {
+			return
+		}
+		result := Fail[U](indexInputError(index, item.Error))
+		if item.Error == nil {
+			value, err := execute(ctx, o, func(ctx context.Context) (U, error) {
+				return f(ctx, item.Value)
+			})
+			result = Success(value)
+			if err != nil {
+				result = Fail[U](indexError(index, err))
+			}
+		}
+		if !send(ctx, out, result) {
+			return
+		}
+		stageEmitted(o)
+	}
+}
+
+// Chain composes two pipeline steps into one, the output of first is the
+// input of second. Chains can be nested to build pipelines of any length.
+//
+// Example:
+//
+//	pipeline := Chain(Chain(parse, enrich), store)
+func Chain[A, B, C any](first StageFunc[A, B], second StageFunc[B, C]) StageFunc[A, C] {
+	return func(ctx context.Context, in Sequence[A]) Sequence[C] {
+		return second(ctx, first(ctx, in))
+	}
+}
+
+// RunPipeline starts the given pipeline step, usually composed with Chain, on
+// the source and returns its output. Cancelling the context shuts the
+// pipeline down: every stage stops its workers and closes its output once,
+// items still buffered between the stages are abandoned.
+//
+// Example:
+//
+//	results := RunPipeline(ctx, Stream(ctx, readOrder), Chain(validate, persist))
+func RunPipeline[T, U any](ctx context.Context, src Sequence[T], stage StageFunc[T, U]) Sequence[U] {
+	return stage(ctx, src)
+}
@@ -0,0 +1,122 @@
+package async
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statsWindow is the number of most recent latencies per task name the
+// quantiles of Stats are computed over
+const statsWindow = 1024
+
+// Stats aggregates the outcomes of Do actions and Stream steps per task name:
+// counts, errors and latency quantiles. Register its OnComplete method with
+// WithOnComplete and inspect it with Report. Recording is lock free, so it
+// can be left on in hot paths.
+//
+// Example:
+//
+//	stats := NewStats()
+//	r := Do(ctx, fetch, WithName("fetch"), WithOnComplete(stats.OnComplete))
+//	...
+//	for name, s := range stats.Report() {
+//	    log.Printf("%s: %d calls, p99 %v", name, s.Count, s.P99)
+//	}
+type Stats struct {
+	tasks sync.Map
+}
+
+// TaskStats is the report of a single task name
+type TaskStats struct {
+	// Count is the total number of completed executions
+	Count int64
+	// Errors is the number of executions that returned an error
+	Errors int64
+	// Mean is the mean latency of all executions
+	Mean time.Duration
+	// Max is the highest latency of all executions
+	Max time.Duration
+	// P50, P90 and P99 are the latency quantiles of the most recent 1024
+	// executions
+	P50, P90, P99 time.Duration
+}
+
+type taskStats struct {
+	count, errors, total, max atomic.Int64
+	// samples is a ring of the latest latencies, next the total number of
+	// samples written
+	samples [statsWindow]atomic.Int64
+	next    atomic.Uint64
+}
+
+// NewStats creates an empty statistics collector
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+func (s *Stats) get(name string) *taskStats {
+	if t, ok := s.tasks.Load(name); ok {
+		return t.(*taskStats)
+	}
+	t, _ := s.tasks.LoadOrStore(name, &taskStats{})
+	return t.(*taskStats)
+}
+
+// OnComplete records an execution, it has the signature expected by
+// WithOnComplete
+func (s *Stats) OnComplete(ctx context.Context, name string, d time.Duration, err error) {
+	t := s.get(name)
+	t.count.Add(1)
+	if err != nil {
+		t.errors.Add(1)
+	}
+	t.total.Add(int64(d))
+	for {
+		m := t.max.Load()
+		if int64(d) <= m || t.max.CompareAndSwap(m, int64(d)) {
+			break
+		}
+	}
+	i := t.next.Add(1) - 1
+	t.samples[i%statsWindow].Store(int64(d))
+}
+
+// Report returns the current statistics per task name. Tasks without a name
+// are reported under the empty string. Executions recorded concurrently may
+// be reflected partially.
+func (s *Stats) Report() map[string]TaskStats {
+	report := map[string]TaskStats{}
+	s.tasks.Range(func(key, value any) bool {
+		report[key.(string)] = value.(*taskStats).report()
+		return true
+	})
+	return report
+}
+
+func (t *taskStats) report() TaskStats {
+	r := TaskStats{
+		Count:  t.count.Load(),
+		Errors: t.errors.Load(),
+		Max:    time.Duration(t.max.Load()),
+	}
+	if r.Count > 0 {
+		r.Mean = time.Duration(t.total.Load() / r.Count)
+	}
+	n := min(t.next.Load(), statsWindow)
+	if n == 0 {
+		return r
+	}
+	samples := make([]int64, n)
+	for i := range samples {
+		samples[i] = t.samples[i].Load()
+	}
+	slices.Sort(samples)
+	quantile := func(q float64) time.Duration {
+		return time.Duration(samples[int(q*float64(n-1))])
+	}
+	r.P50, r.P90, r.P99 = quantile(0.5), quantile(0.9), quantile(0.99)
+	return r
+}
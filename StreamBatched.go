@@ -0,0 +1,153 @@
+package async
+
+import (
+	"context"
+	"time"
+
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// defaultBatchSize is the batch size of StreamBatched without WithSendBatching
+const defaultBatchSize = 64
+
+// StreamBatched executes the given step function repeatedly in a goroutine,
+// like StreamState, but sends its results in batches. For very fast step
+// functions the channel operation per result dominates the cost of a stream,
+// grouping the results amortizes it over many of them.
+//
+// The size of the batches is set with WithSendBatching and defaults to 64.
+// A batch is sent once it is full, the step function asks to stop, or the
+// flush interval given to WithSendBatching elapsed. The interval is checked
+// after every step, so a step that blocks delays the batch collected so far.
+//
+// An error of the step function is delivered as error item of its own: the
+// results produced before it are sent first, so the order of results and
+// errors is kept. Use Unbatch to turn the batches back into single results.
+//
+// The stream options apply to the batches, not to the single steps: the
+// hooks, interceptors, spans and rate limit run once per batch, and
+// WithMaxIterations limits the number of batches. WithMaxErrors and
+// WithStopOnError see every error.
+//
+// Supported options:
+//   - WithClock
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithMaxDuration
+//   - WithMaxErrors
+//   - WithMaxIterations
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRateLimit
+//   - WithRecover
+//   - WithSendBatching
+//   - WithSpanPerStep
+//   - WithStopOnError
+//   - WithTracer
+//
+// Example:
+//
+//	batches := StreamBatched(ctx, func(ctx context.Context) (Event, error, bool) {
+//	    return decoder.Next()
+//	}, WithSendBatching(256, 10*time.Millisecond))
+//	for result := range batches {
+//	    if result.Error != nil {
+//	        log.Printf("error: %v", result.Error)
+//	        continue
+//	    }
+//	    store.InsertAll(result.Value)
+//	}
+func StreamBatched[T any](ctx context.Context, step func(ctx context.Context) (T, error, bool), opts ...Option) Sequence[[]T] {
+	o := newOptions(opts)
+	site := callSite(o)
+	b := &batcher[T]{step: step, size: o.batchSize, flush: o.batchFlush, clock: o.clock}
+	if b.size <= 0 {
+		b.size = defaultBatchSize
+	}
+	r := streamOut[[]T](o)
+	spawnTracked("StreamBatched", o, func(e *registry.Entry) {
+		defer close(r)
+		runStream(ctx, o, true, r, e, site, b.next)
+	})
+	return r
+}
+
+// batcher collects the results of a step function into batches
+type batcher[T any] struct {
+	step  func(ctx context.Context) (T, error, bool)
+	size  int
+	flush time.Duration
+	clock Clock
+	// pending is an error that is delivered after the current batch
+	pending     error
+	pendingMore bool
+	hasPending  bool
+}
+
+// next is the step function of the batched stream
+func (b *batcher[T]) next(ctx context.Context) ([]T, error, bool) {
+	if b.hasPending {
+		b.hasPending = false
+		return nil, b.pending, b.pendingMore
+	}
+	var start time.Time
+	if b.flush > 0 {
+		start = b.clock.Now()
+	}
+	batch := make([]T, 0, b.size)
+	for len(batch) < b.size {
+		value, err, more := b.step(ctx)
+		if err != nil {
+			if len(batch) == 0 {
+				return nil, err, more
+			}
+			b.pending, b.pendingMore, b.hasPending = err, more, true
+			return batch, nil, true
+		}
+		batch = append(batch, value)
+		if !more {
+			return batch, nil, false
+		}
+		if ctx.Err() != nil || b.flush > 0 && b.clock.Now().Sub(start) >= b.flush {
+			break
+		}
+	}
+	return batch, nil, true
+}
+
+// Unbatch flattens a sequence of batches, e.g. produced by StreamBatched, into
+// a sequence of single results. Error items are forwarded unchanged.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Example:
+//
+//	events := Unbatch(ctx, StreamBatched(ctx, next))
+func Unbatch[T any](ctx context.Context, in Sequence[[]T]) Sequence[T] {
+	r := make(Sequence[T])
+	spawn("Unbatch", nil, func() {
+		defer close(r)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				if !send(ctx, r, Fail[T](item.Error)) {
+					return
+				}
+				continue
+			}
+			for _, v := range item.Value {
+				if !send(ctx, r, Success(v)) {
+					return
+				}
+			}
+		}
+	})
+	return r
+}
@@ -0,0 +1,102 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+func TestStreamBatchedFlushEvery(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	n := 0
+	batches := async.StreamBatched(ctx, func(ctx context.Context) (int, error, bool) {
+		// every step takes 10ms of fake time
+		clock.Advance(10 * time.Millisecond)
+		n++
+		return n, nil, n < 7
+	}, async.WithSendBatching(100, 25*time.Millisecond), async.WithClock(clock))
+	asynctest.ExpectValues(t, ctx, batches, [][]int{{1, 2, 3}, {4, 5, 6}, {7}})
+}
+
+func TestStreamBatchedKeepsErrorOrder(t *testing.T) {
+	ctx := context.Background()
+	n := 0
+	batches := async.StreamBatched(ctx, func(ctx context.Context) (int, error, bool) {
+		n++
+		if n == 3 {
+			return 0, errDownstream, true
+		}
+		return n, nil, n < 5
+	}, async.WithSendBatching(10, 0))
+
+	var got []any
+	for item := range batches {
+		if item.Error != nil {
+			if !errors.Is(item.Error, errDownstream) {
+				t.Fatalf("want the step error, got %v", item.Error)
+			}
+			got = append(got, errDownstream)
+		} else {
+			got = append(got, item.Value)
+		}
+	}
+	want := []any{[]int{1, 2}, errDownstream, []int{4, 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+// tenMillion is the number of items streamed by the batching benchmarks
+const tenMillion = 10_000_000
+
+func BenchmarkStream10M(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		n := 0
+		seq := async.Stream(ctx, func(ctx context.Context) (int, error, bool) {
+			n++
+			return n, nil, n < tenMillion
+		})
+		for range seq {
+		}
+	}
+}
+
+func BenchmarkStreamBatched10M(b *testing.B) {
+	ctx := context.Background()
+	for _, size := range []int{64, 1024} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				n := 0
+				seq := async.StreamBatched(ctx, func(ctx context.Context) (int, error, bool) {
+					n++
+					return n, nil, n < tenMillion
+				}, async.WithSendBatching(size, 0))
+				for range async.Unbatch(ctx, seq) {
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("batch=%d/consume-batches", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				n := 0
+				seq := async.StreamBatched(ctx, func(ctx context.Context) (int, error, bool) {
+					n++
+					return n, nil, n < tenMillion
+				}, async.WithSendBatching(size, 0))
+				for range seq {
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package async
+
+import (
+	"context"
+
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// checkpointer threads the state of StreamCheckpointed and persists it
+type checkpointer[S, T any] struct {
+	load  func(ctx context.Context) (S, error)
+	save  func(ctx context.Context, s S) error
+	step  func(ctx context.Context, s S) (S, T, error, bool)
+	every int
+	stop  bool
+
+	state  S
+	loaded bool
+	steps  int
+	// pending is a save error that is delivered after the current item, see
+	// saveError
+	pending     error
+	pendingMore bool
+}
+
+// StreamCheckpointed is StreamState with a persisted state: the initial state
+// is loaded with load and the state is saved with save after every
+// successful step, so that a stream restarted after a crash resumes from its
+// last checkpoint. With WithCheckpointEvery the state is only saved every n
+// successful steps. The state is saved in addition when a successful step
+// ends the stream. A stream that ends with a failed step, or because of a
+// limit or the context, keeps its last checkpoint, the steps since then are
+// repeated after a restart.
+//
+// If load fails, the error is emitted as single item and the stream ends. If
+// save fails, the error is emitted as error item after the item of the step
+// and the stream goes on, unless WithStopOnSaveError is set. The state of a
+// failed step is threaded to the next step as with StreamState, it is only
+// persisted with the next checkpoint.
+//
+// StreamCheckpointed supports the same options as Stream, they apply to the
+// steps including the checkpoints. The error item of a failed save is no step
+// of its own: it does not count against WithMaxIterations and is not passed to
+// the hooks of WithOnStart and WithOnComplete.
+//
+// Example:
+//
+//	seq := StreamCheckpointed(ctx,
+//	    func(ctx context.Context) (string, error) {
+//	        return store.Get(ctx, "offset")
+//	    },
+//	    func(ctx context.Context, offset string) error {
+//	        return store.Put(ctx, "offset", offset)
+//	    },
+//	    func(ctx context.Context, offset string) (string, Event, error, bool) {
+//	        e, err := feed.Next(ctx, offset)
+//	        return e.Offset, e, err, true
+//	    },
+//	    WithCheckpointEvery(100),
+//	)
+func StreamCheckpointed[S, T any](ctx context.Context, load func(ctx context.Context) (S, error), save func(ctx context.Context, s S) error, step func(ctx context.Context, s S) (S, T, error, bool), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	site := callSite(o)
+	c := &checkpointer[S, T]{load: load, save: save, step: step, every: max(o.checkpointEvery, 1), stop: o.stopOnSaveError}
+	r := streamOut[T](o)
+	spawnTracked("StreamCheckpointed", o, func(e *registry.Entry) {
+		defer close(r)
+		runStreamTrailing(ctx, o, true, r, e, site, c.next, c.saveError)
+	})
+	return r
+}
+
+// next runs a single step and saves the state if a checkpoint is due
+func (c *checkpointer[S, T]) next(ctx context.Context) (result T, err error, more bool) {
+	if !c.loaded {
+		if c.state, err = c.load(ctx); err != nil {
+			return result, err, false
+		}
+		c.loaded = true
+	}
+	c.state, result, err, more = c.step(ctx, c.state)
+	if err != nil {
+		return result, err, more
+	}
+	c.steps++
+	if c.steps%c.every == 0 || !more {
+		if serr := c.save(ctx, c.state); serr != nil {
+			c.pending, c.pendingMore = serr, more && !c.stop
+		}
+	}
+	return result, nil, more
+}
+
+// saveError returns the error of the save of the last step, if it failed,
+// and whether the stream goes on after it
+func (c *checkpointer[S, T]) saveError() (err error, more, ok bool) {
+	if c.pending == nil {
+		return nil, false, false
+	}
+	err, more = c.pending, c.pendingMore
+	c.pending = nil
+	return err, more, true
+}
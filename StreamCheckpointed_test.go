@@ -0,0 +1,139 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// memoryStore is an in-memory checkpoint store
+type memoryStore struct {
+	mu    sync.Mutex
+	state int
+	saves []int
+	// fail makes the saves of these states fail
+	fail map[int]bool
+}
+
+func (m *memoryStore) load(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state, nil
+}
+
+func (m *memoryStore) save(ctx context.Context, s int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[s] {
+		return errDownstream
+	}
+	m.state = s
+	m.saves = append(m.saves, s)
+	return nil
+}
+
+// upTo counts the state up and stops once it reaches limit
+func upTo(limit int) func(ctx context.Context, s int) (int, int, error, bool) {
+	return func(ctx context.Context, s int) (int, int, error, bool) {
+		return s + 1, s + 1, nil, s+1 < limit
+	}
+}
+
+func TestStreamCheckpointedResumes(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	values, errs := items(async.StreamCheckpointed(ctx, store.load, store.save, upTo(3)))
+	if !slices.Equal(values, []int{1, 2, 3}) || len(errs) != 0 {
+		t.Fatalf("want values 1 to 3, got %v and %v", values, errs)
+	}
+	if !slices.Equal(store.saves, []int{1, 2, 3}) {
+		t.Fatalf("want a checkpoint after every step, got %v", store.saves)
+	}
+
+	// a restarted stream continues from the checkpoint
+	values, _ = items(async.StreamCheckpointed(ctx, store.load, store.save, upTo(5)))
+	if !slices.Equal(values, []int{4, 5}) {
+		t.Fatalf("want the stream resumed after 3, got %v", values)
+	}
+}
+
+func TestStreamCheckpointedEvery(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	items(async.StreamCheckpointed(ctx, store.load, store.save, upTo(7), async.WithCheckpointEvery(3)))
+	if !slices.Equal(store.saves, []int{3, 6, 7}) {
+		t.Fatalf("want every third state and the final one saved, got %v", store.saves)
+	}
+}
+
+func TestStreamCheckpointedFailedLastStep(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	seq := async.StreamCheckpointed(ctx, store.load, store.save, func(ctx context.Context, s int) (int, int, error, bool) {
+		if s == 2 {
+			return s + 1, 0, errDownstream, false
+		}
+		return s + 1, s + 1, nil, true
+	}, async.WithCheckpointEvery(5))
+	if _, errs := items(seq); len(errs) != 1 {
+		t.Fatalf("want the step error, got %v", errs)
+	}
+	if len(store.saves) != 0 {
+		t.Fatalf("want the last checkpoint kept after a failed last step, got saves %v", store.saves)
+	}
+}
+
+func TestStreamCheckpointedSaveErrors(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{fail: map[int]bool{2: true}}
+	values, errs := items(async.StreamCheckpointed(ctx, store.load, store.save, upTo(3)))
+	if !slices.Equal(values, []int{1, 2, 3}) || len(errs) != 1 || !errors.Is(errs[0], errDownstream) {
+		t.Fatalf("want the save error as item between the values, got %v and %v", values, errs)
+	}
+
+	store = &memoryStore{fail: map[int]bool{2: true}}
+	values, errs = items(async.StreamCheckpointed(ctx, store.load, store.save, upTo(3), async.WithStopOnSaveError()))
+	if !slices.Equal(values, []int{1, 2}) || len(errs) != 1 {
+		t.Fatalf("want the stream stopped after the save error, got %v and %v", values, errs)
+	}
+}
+
+func TestStreamCheckpointedSaveErrorNoStep(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{fail: map[int]bool{2: true}}
+	var starts, completes int
+	seq := async.StreamCheckpointed(ctx, store.load, store.save, upTo(10),
+		async.WithMaxIterations(3),
+		async.WithOnStart(func(ctx context.Context, name string) {
+			starts++
+		}),
+		async.WithOnComplete(func(ctx context.Context, name string, d time.Duration, err error) {
+			completes++
+		}))
+	values, errs := items(seq)
+	if !slices.Equal(values, []int{1, 2, 3}) || len(errs) != 2 || !errors.Is(errs[0], errDownstream) || !errors.Is(errs[1], async.ErrMaxIterations) {
+		t.Fatalf("want 3 steps besides the save error, got %v and %v", values, errs)
+	}
+	if starts != 3 || completes != 3 {
+		t.Fatalf("want the hooks called for the 3 steps only, got %d starts and %d completions", starts, completes)
+	}
+}
+
+func TestStreamCheckpointedLoadError(t *testing.T) {
+	ctx := context.Background()
+	steps := 0
+	seq := async.StreamCheckpointed(ctx, func(ctx context.Context) (int, error) {
+		return 0, errDownstream
+	}, (&memoryStore{}).save, func(ctx context.Context, s int) (int, int, error, bool) {
+		steps++
+		return s, s, nil, true
+	})
+	if _, errs := items(seq); len(errs) != 1 || !errors.Is(errs[0], errDownstream) || steps != 0 {
+		t.Fatalf("want the load error as single item without steps, got %v after %d steps", errs, steps)
+	}
+}
@@ -0,0 +1,136 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// StreamConcurrent is Stream with up to workers invocations of the step
+// function running at the same time, which suits steps that are independent
+// network calls. The results are emitted as the steps complete, so their
+// order is not the order the steps were started in, nor is it stable between
+// runs. Use Stream if the order matters.
+//
+// Once a step returns next=false, no further steps are started. The steps in
+// progress are finished and their results are emitted before the returned
+// sequence is closed. With WithStopOnError an error of a step winds the stream
+// down the same way.
+//
+// The step function is invoked from several goroutines at once, so it must
+// be safe for concurrent use, e.g. by taking its work from a channel.
+//
+// The steps run with the instrumentation of Do and their errors are emitted
+// as error items. The stream is a single task like Stream, which carries the
+// TaskID seen by the steps and is reported to the logger, tracer, metrics and
+// the tracker given with WithTracker. The stream is aware of the context: the
+// returned sequence is closed once all steps in progress returned after the
+// context is done.
+//
+// Supported options:
+//   - WithErrorStacks
+//   - WithInterceptors
+//   - WithLogLevels
+//   - WithLogger
+//   - WithName
+//   - WithOnComplete
+//   - WithOnStart
+//   - WithRecover
+//   - WithRunner
+//   - WithStopOnError
+//   - WithTracer
+//   - WithTracker
+//
+// Example:
+//
+//	symbols := make(chan string)
+//	go feedSymbols(ctx, symbols)
+//	quotes := StreamConcurrent(ctx, 8, func(ctx context.Context) (Quote, error, bool) {
+//	    symbol, ok := <-symbols
+//	    if !ok {
+//	        return Quote{}, nil, false
+//	    }
+//	    q, err := api.Quote(ctx, symbol)
+//	    return q, err, true
+//	})
+func StreamConcurrent[T any](ctx context.Context, workers int, step func(ctx context.Context) (T, error, bool), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	site := callSite(o)
+	workers = max(workers, 1)
+	r := make(Sequence[T])
+	spawnTracked("StreamConcurrent", o, func(e *registry.Entry) {
+		defer close(r)
+		ctx, task := beginTask(ctx, o, kindStream)
+		c := &concurrentStream[T]{}
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for range workers {
+			spawn("StreamConcurrent.worker", o, func() {
+				defer wg.Done()
+				c.work(ctx, o, task, site, r, step)
+			})
+		}
+		wg.Wait()
+		if c.reason == "" && ctx.Err() != nil {
+			c.reason = CloseCancelled
+		}
+		task.endStream(c.err, c.reason)
+	})
+	return r
+}
+
+// concurrentStream is the state the workers of StreamConcurrent share
+type concurrentStream[T any] struct {
+	stopped atomic.Bool
+
+	mu sync.Mutex
+	// err is the error of the last emitted item
+	err error
+	// reason is the reason the stream ended
+	reason CloseReason
+}
+
+// work runs steps until the stream is stopped or the context is done
+func (c *concurrentStream[T]) work(ctx context.Context, o *options, task *taskRun, site []uintptr, out Sequence[T], step func(ctx context.Context) (T, error, bool)) {
+	for !c.stopped.Load() && ctx.Err() == nil {
+		var more bool
+		value, err := execute(ctx, o, func(ctx context.Context) (T, error) {
+			value, err, next := step(ctx)
+			more = next
+			return value, err
+		})
+		switch {
+		case !more && panicked(err):
+			c.stop(ClosePanicked)
+		case !more:
+			c.stop(CloseCompleted)
+		case err != nil && o.stopOnError:
+			c.stop(CloseStoppedOnError)
+		}
+		item := Success(value)
+		if err != nil {
+			err = stackError(site, o.name, taskError(ctx, o.name, err))
+			item = Fail[T](err)
+		}
+		if !send(ctx, out, item) {
+			c.stop(CloseConsumerStopped)
+			return
+		}
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		task.emitted(1)
+	}
+}
+
+// stop ends the stream for the given reason, unless it was stopped before
+func (c *concurrentStream[T]) stop(reason CloseReason) {
+	c.stopped.Store(true)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reason == "" {
+		c.reason = reason
+	}
+}
@@ -0,0 +1,168 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// gatedSteps is a step function for StreamConcurrent whose steps take the
+// jobs 0 to n-1 and only return once their gate was opened, so the test
+// controls the latency of every step. Once the jobs are used up, a step
+// returns -1 and ends the stream.
+type gatedSteps struct {
+	jobs    chan int
+	gates   []chan struct{}
+	started chan int
+}
+
+func newGatedSteps(n int) *gatedSteps {
+	g := &gatedSteps{jobs: make(chan int, n), gates: make([]chan struct{}, n), started: make(chan int, n)}
+	for i := range n {
+		g.jobs <- i
+		g.gates[i] = make(chan struct{})
+	}
+	close(g.jobs)
+	return g
+}
+
+func (g *gatedSteps) step(ctx context.Context) (int, error, bool) {
+	job, ok := <-g.jobs
+	if !ok {
+		return -1, nil, false
+	}
+	g.started <- job
+	select {
+	case <-g.gates[job]:
+		return job, nil, true
+	case <-ctx.Done():
+		return job, ctx.Err(), true
+	}
+}
+
+// waitStarted waits until n steps are in progress
+func (g *gatedSteps) waitStarted(t *testing.T, n int) {
+	t.Helper()
+	for range n {
+		select {
+		case <-g.started:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("want %d steps running at the same time", n)
+		}
+	}
+}
+
+// nextJob receives the next result of a job, skipping the final steps of
+// the workers that found no job
+func nextJob(t *testing.T, seq async.Sequence[int]) (int, error) {
+	t.Helper()
+	for {
+		select {
+		case item := <-seq:
+			if item.Value != -1 {
+				return item.Value, item.Error
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("want the result of a job")
+		}
+	}
+}
+
+func TestStreamConcurrentEmitsAsStepsComplete(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	steps := newGatedSteps(4)
+	seq := async.StreamConcurrent(ctx, 4, steps.step)
+	steps.waitStarted(t, 4)
+
+	// the steps complete in reverse order of their start
+	for _, job := range []int{3, 1, 2, 0} {
+		close(steps.gates[job])
+		if got, err := nextJob(t, seq); got != job || err != nil {
+			t.Fatalf("want the result of job %d first, got %d and %v", job, got, err)
+		}
+	}
+	values, _ := items(seq)
+	if slices.ContainsFunc(values, func(v int) bool { return v != -1 }) {
+		t.Fatalf("want only the final steps left, got %v", values)
+	}
+}
+
+func TestStreamConcurrentFinishesStepsInProgress(t *testing.T) {
+	ctx := context.Background()
+	steps := newGatedSteps(2)
+	seq := async.StreamConcurrent(ctx, 3, steps.step)
+	steps.waitStarted(t, 2)
+
+	// the third worker found no job and ended the stream, the steps in
+	// progress are still emitted
+	if item := <-seq; item.Value != -1 {
+		t.Fatalf("want the final step first, got %v", item)
+	}
+	close(steps.gates[0])
+	close(steps.gates[1])
+	values, _ := items(seq)
+	slices.Sort(values)
+	if !slices.Equal(values, []int{0, 1}) {
+		t.Fatalf("want the steps in progress emitted, got %v", values)
+	}
+}
+
+func TestStreamConcurrentIsATask(t *testing.T) {
+	ctx := context.Background()
+	tracker := async.NewTracker()
+	var mu sync.Mutex
+	ids := map[string]bool{}
+	n := 0
+	seq := async.StreamConcurrent(ctx, 4, func(ctx context.Context) (int, error, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids[async.TaskID(ctx)] = true
+		n++
+		return n, nil, n < 20
+	}, async.WithTracker(tracker), async.WithName("quotes"))
+
+	if running := tracker.Running(); !slices.Equal(running, []string{"quotes"}) {
+		t.Fatalf("want the stream tracked while it runs, got %v", running)
+	}
+	items(seq)
+	if err := tracker.Wait(ctx); err != nil {
+		t.Fatalf("want the tracked stream finished, got %v", err)
+	}
+	if len(ids) != 1 || ids[""] {
+		t.Fatalf("want all steps to run in one task, got task IDs %v", ids)
+	}
+}
+
+func TestStreamConcurrentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	steps := newGatedSteps(2)
+	seq := async.StreamConcurrent(ctx, 2, steps.step)
+	steps.waitStarted(t, 2)
+	cancel()
+	asynctest.Drained(t, context.Background(), seq)
+}
+
+func TestStreamConcurrentRecoveredPanic(t *testing.T) {
+	logger, b := logged()
+	var n atomic.Int64
+	_, errs := items(async.StreamConcurrent(context.Background(), 2, func(ctx context.Context) (int, error, bool) {
+		if n.Add(1) == 3 {
+			panic("boom")
+		}
+		return 0, nil, true
+	}, async.WithRecover(), async.WithLogger(logger)))
+	var perr *async.PanicError
+	if len(errs) != 1 || !errors.As(errs[0], &perr) {
+		t.Fatalf("want the panic emitted, got %v", errs)
+	}
+	records := b.records(t)
+	expectRecord(t, records[len(records)-1], map[string]any{"reason": string(async.ClosePanicked)})
+}
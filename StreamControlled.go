@@ -0,0 +1,116 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// StreamControl pauses and resumes a stream created with StreamControlled.
+// Its methods are safe for concurrent use and idempotent.
+type StreamControl struct {
+	mu     sync.Mutex
+	paused bool
+	// resume is closed when the stream is resumed
+	resume chan struct{}
+}
+
+// Pause stops the stream from invoking its step function. A step that is in
+// progress is finished and its result is delivered, nothing is buffered.
+func (c *StreamControl) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		c.paused = true
+		c.resume = make(chan struct{})
+	}
+}
+
+// Resume lets a paused stream continue with the next step
+func (c *StreamControl) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		close(c.resume)
+	}
+}
+
+// Paused reports whether the stream is paused
+func (c *StreamControl) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// wait blocks while the stream is paused
+func (c *StreamControl) wait(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		paused, resume := c.paused, c.resume
+		c.mu.Unlock()
+		if !paused {
+			return nil
+		}
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// StreamControlled is StreamState without a state, returning a handle that
+// pauses and resumes the stream in addition to the sequence. While it is
+// paused, the stream does not invoke its step function, Resume continues
+// where it left off. This is meant to halt an ingestion temporarily, e.g.
+// while a downstream store is being compacted.
+//
+// Like StreamState, the stream is aware of the context: cancelling it ends
+// the stream even while it is paused.
+//
+// StreamControlled supports the same options as Stream. A limiter set with
+// WithRateLimit is waited on after the stream was resumed.
+//
+// Example:
+//
+//	events, control := StreamControlled(ctx, consumer.Next)
+//	go func() {
+//	    for range compactions {
+//	        control.Pause()
+//	        <-compactionDone
+//	        control.Resume()
+//	    }
+//	}()
+func StreamControlled[T any](ctx context.Context, step func(ctx context.Context) (T, error, bool), opts ...Option) (Sequence[T], *StreamControl) {
+	c := &StreamControl{}
+	opts = append(opts[:len(opts):len(opts)], withGate(c.wait))
+	seq := StreamState(ctx, struct{}{}, func(ctx context.Context, s struct{}) (struct{}, T, error, bool) {
+		result, err, more := step(ctx)
+		return s, result, err, more
+	}, opts...)
+	return seq, c
+}
+
+// withGate makes a stream wait on gate before every step, ahead of the
+// limiter set with WithRateLimit
+func withGate(gate func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.limiter = gatedLimiter{gate: gate, next: o.limiter}
+	}
+}
+
+// gatedLimiter waits on a gate and then on a limiter
+type gatedLimiter struct {
+	gate func(ctx context.Context) error
+	next Limiter
+}
+
+func (l gatedLimiter) Wait(ctx context.Context) error {
+	if err := l.gate(ctx); err != nil {
+		return err
+	}
+	if l.next == nil {
+		return nil
+	}
+	return l.next.Wait(ctx)
+}
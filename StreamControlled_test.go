@@ -0,0 +1,91 @@
+package async_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// countedSteps returns a step function counting its invocations in n
+func countedSteps(n *atomic.Int64) func(ctx context.Context) (int64, error, bool) {
+	return func(ctx context.Context) (int64, error, bool) {
+		return n.Add(1), nil, true
+	}
+}
+
+// pausedAfter pauses the stream and returns the last item received. The
+// item of a step that was already in progress is delivered.
+func pausedAfter(t *testing.T, seq async.Sequence[int64], c *async.StreamControl, n *atomic.Int64) int64 {
+	t.Helper()
+	first := <-seq
+	c.Pause()
+	// a step that passed the gate before Pause has counted itself by now
+	time.Sleep(20 * time.Millisecond)
+	if n.Load() > first.Value {
+		return (<-seq).Value
+	}
+	return first.Value
+}
+
+func TestStreamControlledPause(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var n atomic.Int64
+	seq, c := async.StreamControlled(ctx, countedSteps(&n))
+	last := pausedAfter(t, seq, c, &n)
+	if !c.Paused() {
+		t.Fatal("want the stream paused")
+	}
+
+	select {
+	case item := <-seq:
+		t.Fatalf("want no item while paused, got %v", item)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if got := n.Load(); got != last {
+		t.Fatalf("want no step while paused, %d steps ran for %d items", got, last)
+	}
+
+	// Resume continues where the stream left off
+	c.Resume()
+	if item := <-seq; item.Value != last+1 {
+		t.Fatalf("want the next step after Resume, got %v", item)
+	}
+	cancel()
+	asynctest.Drained(t, context.Background(), seq)
+}
+
+func TestStreamControlledIdempotent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var n atomic.Int64
+	seq, c := async.StreamControlled(ctx, countedSteps(&n))
+	last := pausedAfter(t, seq, c, &n)
+	c.Pause()
+	c.Resume()
+	c.Resume()
+	if c.Paused() {
+		t.Fatal("want the stream resumed")
+	}
+	if item := <-seq; item.Value != last+1 {
+		t.Fatalf("want the next step after Resume, got %v", item)
+	}
+}
+
+func TestStreamControlledCancelledWhilePaused(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	reasons, onClose := closeReason()
+	var n atomic.Int64
+	seq, c := async.StreamControlled(ctx, countedSteps(&n), async.WithOnClose(onClose))
+	pausedAfter(t, seq, c, &n)
+
+	cancel()
+	asynctest.Drained(t, context.Background(), seq)
+	expectReason(t, reasons, async.CloseCancelled)
+}
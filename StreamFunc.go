@@ -0,0 +1,53 @@
+package async
+
+import (
+	"context"
+)
+
+// StreamFunc executes the given producer in a goroutine and returns a
+// Sequence[T] channel that receives every item the producer pushes.
+//
+// Instead of returning one item per call like Stream, the producer calls yield
+// for each item it wants to emit. This makes it easy to emit a variable number
+// of items per underlying operation, e.g. when one API call returns 0..n
+// records.
+//
+// yield blocks until the item was received by the consumer. It returns false
+// once the context is done, in which case the item was not delivered and the
+// producer should stop and return. After yield returned false, every further
+// call returns false as well.
+//
+// If produce returns a non-nil error, it is sent as a final error item. The
+// channel is closed when produce returns.
+//
+// Example:
+//
+//	seq := StreamFunc(ctx, func(ctx context.Context, yield func(Record) bool) error {
+//	    for page := 0; ; page++ {
+//	        records, more, err := fetchPage(ctx, page)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        for _, r := range records {
+//	            if !yield(r) {
+//	                return nil
+//	            }
+//	        }
+//	        if !more {
+//	            return nil
+//	        }
+//	    }
+//	})
+func StreamFunc[T any](ctx context.Context, produce func(ctx context.Context, yield func(T) bool) error) Sequence[T] {
+	r := make(Sequence[T])
+	spawn("StreamFunc", nil, func() {
+		defer close(r)
+		yield := func(v T) bool {
+			return send(ctx, r, Success(v))
+		}
+		if err := produce(ctx, yield); err != nil {
+			send(ctx, r, Fail[T](err))
+		}
+	})
+	return r
+}
@@ -0,0 +1,134 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// Demand is the credit of a stream created with StreamPull, i.e. the number
+// of items the consumer is ready to receive. Its methods are safe for
+// concurrent use.
+type Demand struct {
+	mu     sync.Mutex
+	credit int
+	// more is closed when the credit was increased
+	more chan struct{}
+}
+
+// Request adds n items to the outstanding demand
+func (d *Demand) Request(n int) {
+	if n <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.credit += n
+	close(d.more)
+	d.more = make(chan struct{})
+}
+
+// Outstanding returns the number of items requested but not yet produced
+func (d *Demand) Outstanding() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return max(d.credit, 0)
+}
+
+// wait blocks until there is outstanding demand
+func (d *Demand) wait(ctx context.Context) error {
+	for {
+		d.mu.Lock()
+		credit, more := d.credit, d.more
+		d.mu.Unlock()
+		if credit > 0 {
+			return nil
+		}
+		select {
+		case <-more:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// take returns the outstanding demand
+func (d *Demand) take() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return max(d.credit, 0)
+}
+
+// produced deducts n produced items from the demand
+func (d *Demand) produced(n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.credit -= n
+}
+
+// StreamPull is a stream with credit based backpressure: the step function is
+// only invoked while there is outstanding demand, and it is told how many
+// items are demanded, e.g. to size its next fetch. It should not return more
+// items than demanded, those are delivered anyway, but count against the
+// following demand.
+//
+// By default the demand starts at one item and every item received by the
+// consumer requests another one, so the stream can be consumed like any other
+// sequence and the demand can be raised with Request on the returned handle.
+// With WithManualDemand the demand starts at zero and is only raised by
+// Request.
+//
+// The items of a step are emitted one by one, an error of the step is
+// emitted as error item. The stream is aware of the context like StreamState
+// and supports the same options as Stream, they apply to the steps.
+//
+// Example:
+//
+//	messages, demand := StreamPull(ctx, func(ctx context.Context, n int) ([]Message, error, bool) {
+//	    batch, err := queue.Receive(ctx, min(n, 100))
+//	    return batch, err, true
+//	})
+//	demand.Request(49)
+//	for result := range messages {
+//	    handle(result.Value)
+//	}
+func StreamPull[T any](ctx context.Context, step func(ctx context.Context, demand int) ([]T, error, bool), opts ...Option) (Sequence[T], *Demand) {
+	manual := newOptions(opts).manualDemand
+	d := &Demand{more: make(chan struct{})}
+	if !manual {
+		d.credit = 1
+	}
+	opts = append(opts[:len(opts):len(opts)], withGate(d.wait))
+	batches := StreamState(ctx, struct{}{}, func(ctx context.Context, s struct{}) (struct{}, []T, error, bool) {
+		items, err, more := step(ctx, d.take())
+		if err == nil {
+			// the items of a failed step are not emitted
+			d.produced(len(items))
+		}
+		return s, items, err, more
+	}, opts...)
+	r := make(Sequence[T])
+	spawn("StreamPull", nil, func() {
+		defer close(r)
+		for {
+			batch, ok := receive(ctx, batches)
+			if !ok {
+				return
+			}
+			if batch.Error != nil {
+				if !send(ctx, r, Fail[T](batch.Error)) {
+					return
+				}
+				continue
+			}
+			for _, v := range batch.Value {
+				if !send(ctx, r, Success(v)) {
+					return
+				}
+				if !manual {
+					d.Request(1)
+				}
+			}
+		}
+	})
+	return r, d
+}
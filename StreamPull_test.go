@@ -0,0 +1,36 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestStreamPullFailedStepKeepsDemand(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	steps := 0
+	seq, demand := async.StreamPull(ctx, func(ctx context.Context, n int) ([]int, error, bool) {
+		steps++
+		if steps == 1 {
+			// the items of a failed step are discarded
+			return []int{1, 2}, errDownstream, true
+		}
+		return []int{3}, nil, false
+	}, async.WithManualDemand())
+	demand.Request(3)
+
+	if item := <-seq; item.Error == nil {
+		t.Fatalf("want the step error first, got %v", item)
+	}
+	if item := <-seq; item.Value != 3 || item.Error != nil {
+		t.Fatalf("want the item of the second step, got %v", item)
+	}
+	if _, ok := <-seq; ok {
+		t.Fatal("want the stream ended after the last step")
+	}
+	if got := demand.Outstanding(); got != 2 {
+		t.Fatalf("want only the emitted item deducted from the demand, got %d outstanding", got)
+	}
+}
@@ -0,0 +1,260 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// ErrMaxIterations is wrapped by the final error item of a stream that was
+// stopped because it reached the cap set with WithMaxIterations.
+var ErrMaxIterations = errors.New("async: maximum number of iterations reached")
+
+// streamRunner implements the loop shared by the stream constructors
+type streamRunner[T any] struct {
+	ctx context.Context
+	o   *options
+	out Sequence[T]
+	// aware makes the stream stop as soon as the context is done
+	aware bool
+	entry *registry.Entry
+	task  *taskRun
+	// site is the call site recorded for WithErrorStacks
+	site []uintptr
+	// trailing yields an error item to emit after the item of a step, see
+	// runStreamTrailing
+	trailing func() (err error, more, ok bool)
+	// err is the error of the last emitted item
+	err error
+	// reason is the reason the stream ended
+	reason CloseReason
+	// deadline fires when the maximum duration of the stream elapsed
+	deadline <-chan time.Time
+	expired  bool
+}
+
+// runStream calls step repeatedly and sends the results on out until the step
+// function or one of the options ends the stream. If aware is true, the
+// stream stops as soon as the context is done.
+func runStream[T any](ctx context.Context, o *options, aware bool, out Sequence[T], e *registry.Entry, site []uintptr, step func(ctx context.Context) (T, error, bool)) {
+	runStreamTrailing(ctx, o, aware, out, e, site, step, nil)
+}
+
+// runStreamTrailing is runStream with an error item that may trail the item of
+// a step. After every step, trailing is asked for it: if ok, err is emitted
+// and more replaces whether the step asked to continue. The trailing item is
+// no step of its own, it does not count as iteration and is not passed to the
+// hooks.
+func runStreamTrailing[T any](ctx context.Context, o *options, aware bool, out Sequence[T], e *registry.Entry, site []uintptr, step func(ctx context.Context) (T, error, bool), trailing func() (err error, more, ok bool)) {
+	ctx, task := beginTask(ctx, o, kindStream)
+	s := &streamRunner[T]{ctx: ctx, o: o, out: out, aware: aware, entry: e, task: task, site: site, trailing: trailing}
+	defer func() {
+		if s.reason == "" && ctx.Err() != nil {
+			s.reason = CloseCancelled
+		}
+		s.task.endStream(s.err, s.reason)
+		if o.onClose != nil {
+			o.onClose(s.reason)
+		}
+	}()
+	if o.maxDuration > 0 {
+		t := o.clock.NewTimer(o.maxDuration)
+		defer t.Stop()
+		s.deadline = t.C()
+	}
+	s.run(step)
+	if s.expired {
+		s.final(Fail[T](maxDurationError(o.maxDuration)))
+	}
+}
+
+func (s *streamRunner[T]) run(step func(ctx context.Context) (T, error, bool)) {
+	budget := errorBudget{max: s.o.maxErrors}
+	for iteration := 1; s.proceed(); iteration++ {
+		if s.o.limiter != nil {
+			if err := s.o.limiter.Wait(s.ctx); err != nil {
+				if s.emit(Fail[T](err)) {
+					s.stop(CloseStoppedOnError)
+				}
+				return
+			}
+		}
+		var next bool
+		result, err := s.step(func(ctx context.Context) (T, error) {
+			result, err, more := s.invoke(ctx, step)
+			next = more
+			return result, err
+		})
+		if !s.emitCounted(result, err, &budget) {
+			return
+		}
+		if s.trailing != nil {
+			if terr, more, ok := s.trailing(); ok {
+				var zero T
+				err, next = taskError(s.ctx, s.o.name, terr), more
+				if !s.emitCounted(zero, err, &budget) {
+					return
+				}
+			}
+		}
+		if !next && panicked(err) {
+			s.stop(ClosePanicked)
+			return
+		}
+		if !next {
+			s.stop(CloseCompleted)
+			return
+		}
+		if err != nil && s.o.stopOnError {
+			s.stop(CloseStoppedOnError)
+			return
+		}
+		if s.o.maxIterations > 0 && iteration >= s.o.maxIterations {
+			s.emit(Fail[T](fmt.Errorf("%w (%d)", ErrMaxIterations, s.o.maxIterations)))
+			s.stop(CloseLimitExceeded)
+			return
+		}
+	}
+}
+
+// emitCounted emits the outcome of a step, or the error ending the stream if
+// err exhausts the error budget, and reports whether the stream may go on
+func (s *streamRunner[T]) emitCounted(result T, err error, budget *errorBudget) bool {
+	if err == nil {
+		return s.emit(Success(result))
+	}
+	if final := budget.add(err); final != nil {
+		s.emit(Fail[T](final))
+		s.stop(CloseLimitExceeded)
+		return false
+	}
+	return s.emit(Fail[T](err))
+}
+
+// stop records the reason the stream ended, unless one was recorded before
+func (s *streamRunner[T]) stop(reason CloseReason) {
+	if s.reason == "" {
+		s.reason = reason
+	}
+}
+
+// step executes a single step, in its own span if WithSpanPerStep is set
+func (s *streamRunner[T]) step(fn func(ctx context.Context) (T, error)) (T, error) {
+	if s.o.tracer == nil || !s.o.spanPerStep {
+		result, err := execute(s.ctx, s.o, fn)
+		return result, taskError(s.ctx, s.o.name, err)
+	}
+	ctx, end := s.o.tracer.Start(s.ctx, s.task.spanName())
+	result, err := execute(ctx, s.o, fn)
+	end(err)
+	return result, taskError(s.ctx, s.o.name, err)
+}
+
+// invoke calls the step function, with a context of its own if WithStepTimeout
+// is set. The context is cancelled as soon as the step returned, and an
+// overrun turns the result into a timeout error.
+func (s *streamRunner[T]) invoke(ctx context.Context, step func(ctx context.Context) (T, error, bool)) (T, error, bool) {
+	if s.o.stepTimeout <= 0 {
+		return step(ctx)
+	}
+	sctx, cancel := withTimeout(ctx, s.o.clock, s.o.stepTimeout)
+	defer cancel()
+	result, err, more := step(sctx)
+	if sctx.Err() != nil && ctx.Err() == nil {
+		var zero T
+		return zero, stepTimeoutError(s.o.stepTimeout), more
+	}
+	return result, err, more
+}
+
+// proceed reports whether the next step may be started
+func (s *streamRunner[T]) proceed() bool {
+	if s.aware && s.ctx.Err() != nil {
+		s.stop(CloseCancelled)
+		return false
+	}
+	select {
+	case <-s.deadline:
+		s.expired = true
+		s.stop(CloseLimitExceeded)
+		return false
+	default:
+		return true
+	}
+}
+
+// emit sends the given item and reports whether the stream may go on
+func (s *streamRunner[T]) emit(item _Result[T]) bool {
+	var done <-chan struct{}
+	if s.aware {
+		if s.ctx.Err() != nil {
+			s.stop(CloseCancelled)
+			return false
+		}
+		done = s.ctx.Done()
+	} else if s.o.onClose != nil {
+		// the callback has to run even if the consumer stopped reading
+		done = s.ctx.Done()
+	}
+	s.entry.SetState(stateSending)
+	defer s.entry.SetState(stateRunning)
+	select {
+	case s.out <- s.wrap(item):
+		s.delivered(item)
+		return true
+	case <-done:
+		s.stop(CloseConsumerStopped)
+		return false
+	case <-s.deadline:
+		s.expired = true
+		s.stop(CloseLimitExceeded)
+		return false
+	}
+}
+
+// streamOut returns the sequence a stream sends its items on. A stream with a
+// maximum duration gets a buffer slot for its final item, see final.
+func streamOut[T any](o *options) Sequence[T] {
+	if o.maxDuration > 0 {
+		return make(Sequence[T], 1)
+	}
+	return make(Sequence[T])
+}
+
+// final puts the item that terminates the stream into the buffer slot of the
+// sequence, so that it reaches a slow consumer and the producer still exits
+// if nobody reads anymore. An item still in the slot was not received before
+// the stream ended and is dropped.
+func (s *streamRunner[T]) final(item _Result[T]) {
+	select {
+	case <-s.out:
+	default:
+	}
+	s.out <- s.wrap(item)
+	s.delivered(item)
+}
+
+// wrap adds the call site to the error of the item, see WithErrorStacks
+func (s *streamRunner[T]) wrap(item _Result[T]) _Result[T] {
+	item.Error = stackError(s.site, s.o.name, item.Error)
+	return item
+}
+
+// delivered records an item received by the consumer
+func (s *streamRunner[T]) delivered(item _Result[T]) {
+	s.err = item.Error
+	s.task.emitted(1)
+}
+
+// stepTimeoutError is the error of a step that overran the timeout d
+func stepTimeoutError(d time.Duration) error {
+	return fmt.Errorf("async: step exceeded its timeout of %v: %w", d, context.DeadlineExceeded)
+}
+
+// maxDurationError is the error that terminates a sequence cut short after d
+func maxDurationError(d time.Duration) error {
+	return fmt.Errorf("async: sequence exceeded its maximum duration of %v: %w", d, context.DeadlineExceeded)
+}
@@ -0,0 +1,57 @@
+package async
+
+import (
+	"context"
+
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// StreamState executes the given step function repeatedly in a goroutine,
+// threading a state value through the iterations, and returns a Sequence[T]
+// channel that receives the produced results.
+//
+// It has the same shape as Stream, with the current state passed into and the
+// next state returned from every step. In contrast to Stream, StreamState is
+// aware of the provided context:
+//   - Before each step the context is checked and the stream stops if it is done
+//   - Sending a result is aborted as soon as the context is done, so the
+//     goroutine never stays blocked on a consumer that went away
+//
+// Therefore StreamState is the recommended entry point for new code, while
+// Stream is kept for compatibility.
+//
+// StreamState supports the same options as Stream.
+//
+// For each iteration:
+//   - If step returns an error, a _Result[T] with a non-nil Error field is sent
+//   - If step succeeds, a _Result[T] with the result in the Value field is sent
+//   - If the next boolean is false, the loop terminates and the channel closes
+//
+// Example:
+//
+//	seq := StreamState(ctx, 0, func(ctx context.Context, offset int) (int, []Row, error, bool) {
+//	    rows, err := fetchRows(ctx, offset, 100)
+//	    return offset + len(rows), rows, err, err == nil && len(rows) == 100
+//	})
+//	for result := range seq {
+//	    if result.Error != nil {
+//	        log.Printf("error: %v", result.Error)
+//	        continue
+//	    }
+//	    log.Printf("received: %v", result.Value)
+//	}
+func StreamState[S, T any](ctx context.Context, initial S, step func(ctx context.Context, s S) (S, T, error, bool), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	site := callSite(o)
+	r := streamOut[T](o)
+	spawnTracked("StreamState", o, func(e *registry.Entry) {
+		defer close(r)
+		state := initial
+		runStream(ctx, o, true, r, e, site, func(ctx context.Context) (T, error, bool) {
+			next, result, err, more := step(ctx, state)
+			state = next
+			return result, err, more
+		})
+	})
+	return r
+}
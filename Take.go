@@ -0,0 +1,42 @@
+package async
+
+import (
+	"context"
+)
+
+// Take forwards the first n items of the input, including error items, and
+// closes the returned sequence afterwards. The rest of the input is not
+// consumed, so its producer should be bound to a context that is cancelled
+// once the consumer is done.
+//
+// The returned sequence is closed when n items were forwarded, the input is
+// closed or the context is done.
+//
+// Supported options:
+//   - WithName
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	for result := range Take(ctx, Stream(ctx, poll), 10) {
+//	    log.Printf("received: %v", result.Value)
+//	}
+func Take[T any](ctx context.Context, in Sequence[T], n int, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Take", o, func() {
+		defer close(r)
+		for taken := 0; taken < n; taken++ {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+			stageEmitted(o)
+		}
+	})
+	return r
+}
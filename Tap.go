@@ -0,0 +1,42 @@
+package async
+
+import (
+	"context"
+)
+
+// Tap invokes fn for every successful item of the input and forwards all
+// items unchanged. It is meant for side effects such as logging or counting.
+// fn runs synchronously before the item is forwarded, so it should be fast.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithName
+//
+// Example:
+//
+//	logged := Tap(ctx, orders, func(o Order) {
+//	    log.Printf("processing order %s", o.ID)
+//	})
+func Tap[T any](ctx context.Context, in Sequence[T], fn func(v T), opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Tap", o, func() {
+		defer close(r)
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error == nil {
+				fn(item.Value)
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+			stageEmitted(o)
+		}
+	})
+	return r
+}
@@ -0,0 +1,227 @@
+package async
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// taskInfo is the metadata of an async task stored in its context
+type taskInfo struct {
+	id     uint64
+	name   string
+	parent uint64
+}
+
+type taskInfoKey struct{}
+
+// lastTaskID is the source of the task IDs of the process
+var lastTaskID atomic.Uint64
+
+// formatTaskID formats a task ID, the zero ID is formatted as empty string
+func formatTaskID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+// currentTask returns the metadata of the task the context belongs to or nil
+func currentTask(ctx context.Context) *taskInfo {
+	info, _ := ctx.Value(taskInfoKey{}).(*taskInfo)
+	return info
+}
+
+// TaskName returns the name of the async task the context belongs to, as set
+// with WithName. It returns an empty string if the task has no name or the
+// context does not belong to a task.
+func TaskName(ctx context.Context) string {
+	if info := currentTask(ctx); info != nil {
+		return info.name
+	}
+	return ""
+}
+
+// TaskID returns the ID of the async task the context belongs to. Every Do
+// and Stream invocation gets an ID that is unique within the process. It
+// returns an empty string if the context does not belong to a task.
+func TaskID(ctx context.Context) string {
+	if info := currentTask(ctx); info != nil {
+		return formatTaskID(info.id)
+	}
+	return ""
+}
+
+// ParentTaskID returns the ID of the task that started the async task the
+// context belongs to, i.e. the task whose context was passed to Do or Stream.
+// Following the parents forms a simple causality chain. It returns an empty
+// string for tasks started outside of another task.
+func ParentTaskID(ctx context.Context) string {
+	if info := currentTask(ctx); info != nil {
+		return formatTaskID(info.parent)
+	}
+	return ""
+}
+
+// execute runs a single action or step with the instrumentation of the given
+// options: lifecycle hooks, interceptors and panic handling. A panic is
+// reported to the hooks as *PanicError and then either returned as error, if
+// WithRecover is set, or continued. It holds a slot of the cap set with
+// SetMaxConcurrency while fn executes.
+func execute[T any](ctx context.Context, o *options, fn func(ctx context.Context) (T, error)) (result T, err error) {
+	counted, err := acquireSlot(ctx)
+	if err != nil {
+		return result, err
+	}
+	if counted {
+		defer releaseSlot()
+	}
+	if o.onStart != nil {
+		o.onStart(ctx, o.name)
+	}
+	start := o.clock.Now()
+	settled := false
+	defer func() {
+		if settled {
+			return
+		}
+		p := recover()
+		if p == nil {
+			// the goroutine is exiting via runtime.Goexit
+			return
+		}
+		perr := newPanicError(p)
+		o.complete(ctx, start, perr)
+		if !o.recover {
+			o.logPanic(ctx, perr)
+			panic(p)
+		}
+		err = perr
+	}()
+	result, err = intercept(ctx, o, fn)
+	settled = true
+	if err != nil && err == ctx.Err() {
+		// report the cause of the cancellation, see CancelError
+		err = ctxError(ctx)
+	}
+	o.complete(ctx, start, err)
+	return result, err
+}
+
+// complete invokes the completion hook
+func (o *options) complete(ctx context.Context, start time.Time, err error) {
+	if o.onComplete != nil {
+		o.onComplete(ctx, o.name, o.clock.Now().Sub(start), err)
+	}
+}
+
+// taskKind distinguishes the goroutines started by Do and Stream
+type taskKind string
+
+const (
+	kindDo     taskKind = "Do"
+	kindStream taskKind = "Stream"
+)
+
+// taskRun tracks the lifetime of the goroutine of a Do or Stream. It is also
+// the context of the task, which carries its metadata, so that starting a
+// task costs a single allocation.
+type taskRun struct {
+	context.Context
+	o       *options
+	kind    taskKind
+	info    taskInfo
+	ctx     context.Context
+	metrics Metrics
+	start   time.Time
+	endSpan func(err error)
+	// untrack releases the context derived for WithTracker
+	untrack func()
+}
+
+// Value returns the metadata of the task or looks the key up in the parent
+func (t *taskRun) Value(key any) any {
+	if key == (taskInfoKey{}) {
+		return &t.info
+	}
+	return t.Context.Value(key)
+}
+
+// beginTask reports the start of a Do or Stream goroutine and returns the
+// context the task runs with
+func beginTask(ctx context.Context, o *options, kind taskKind) (context.Context, *taskRun) {
+	t := &taskRun{}
+	return t.begin(ctx, o, kind), t
+}
+
+// begin is beginTask for a taskRun embedded in the state of a goroutine, so
+// that it is not allocated on its own
+func (t *taskRun) begin(ctx context.Context, o *options, kind taskKind) context.Context {
+	var untrack func()
+	if o.tracker != nil {
+		ctx, untrack = o.tracker.derive(ctx)
+	}
+	*t = taskRun{
+		Context: ctx,
+		o:       o,
+		kind:    kind,
+		info:    taskInfo{id: lastTaskID.Add(1), name: o.name},
+		metrics: currentMetrics(),
+		start:   o.clock.Now(),
+		untrack: untrack,
+	}
+	if parent := currentTask(ctx); parent != nil {
+		t.info.parent = parent.id
+	}
+	ctx = t
+	if o.tracer != nil && (kind == kindDo || !o.spanPerStep) {
+		ctx, t.endSpan = o.tracer.Start(ctx, t.spanName())
+	}
+	t.ctx = ctx
+	if t.metrics != nil {
+		t.metrics.TaskStarted(o.name)
+	}
+	o.logStart(t)
+	return ctx
+}
+
+// spanName is the name of the spans created for the task
+func (t *taskRun) spanName() string {
+	if t.o.name != "" {
+		return t.o.name
+	}
+	return "async." + string(t.kind)
+}
+
+// emitted reports items emitted by a Stream
+func (t *taskRun) emitted(n int) {
+	if t.metrics != nil {
+		t.metrics.ItemsEmitted(t.o.name, n)
+	}
+}
+
+// end reports the end of a Do goroutine with its final error
+func (t *taskRun) end(err error) {
+	t.finish(err, "")
+}
+
+// endStream reports the end of a Stream goroutine with the error of its last
+// item and the reason it ended
+func (t *taskRun) endStream(err error, reason CloseReason) {
+	t.finish(err, reason)
+}
+
+func (t *taskRun) finish(err error, reason CloseReason) {
+	d := t.o.clock.Now().Sub(t.start)
+	if t.metrics != nil {
+		t.metrics.TaskFinished(t.o.name, d, err)
+	}
+	t.o.logEnd(t, d, err, reason)
+	if t.endSpan != nil {
+		t.endSpan(err)
+	}
+	if t.untrack != nil {
+		t.untrack()
+	}
+}
@@ -0,0 +1,68 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+// taskIDs are the IDs seen by a task
+type taskIDs struct {
+	id, parent, name string
+}
+
+func currentIDs(ctx context.Context) taskIDs {
+	return taskIDs{id: async.TaskID(ctx), parent: async.ParentTaskID(ctx), name: async.TaskName(ctx)}
+}
+
+func TestTaskIDsNested(t *testing.T) {
+	ctx := context.Background()
+	if got := currentIDs(ctx); got != (taskIDs{}) {
+		t.Fatalf("want no task outside of Do, got %+v", got)
+	}
+	var outer, inner, step taskIDs
+	<-async.Do(ctx, func(ctx context.Context) (int, error) {
+		outer = currentIDs(ctx)
+		<-async.Do(ctx, func(ctx context.Context) (int, error) {
+			inner = currentIDs(ctx)
+			for range async.Stream(ctx, func(ctx context.Context) (int, error, bool) {
+				step = currentIDs(ctx)
+				return 0, nil, false
+			}) {
+			}
+			return 0, nil
+		}, async.WithName("inner"))
+		return 0, nil
+	}, async.WithName("outer"))
+
+	if outer.id == "" || outer.parent != "" || outer.name != "outer" {
+		t.Fatalf("want a top-level task, got %+v", outer)
+	}
+	if inner.id == "" || inner.id == outer.id || inner.parent != outer.id || inner.name != "inner" {
+		t.Fatalf("want a child of %s, got %+v", outer.id, inner)
+	}
+	if step.id == "" || step.id == inner.id || step.parent != inner.id || step.name != "" {
+		t.Fatalf("want an unnamed child of %s, got %+v", inner.id, step)
+	}
+}
+
+func TestTaskIDsLogged(t *testing.T) {
+	logger, b := logged()
+	var outer, inner taskIDs
+	<-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		outer = currentIDs(ctx)
+		<-async.Do(ctx, func(ctx context.Context) (int, error) {
+			inner = currentIDs(ctx)
+			return 0, nil
+		}, async.WithLogger(logger))
+		return 0, nil
+	})
+	records := b.records(t)
+	if len(records) != 2 {
+		t.Fatalf("want the records of the inner task, got %v", records)
+	}
+	for _, record := range records {
+		expectRecord(t, record, map[string]any{"task_id": inner.id, "parent_task_id": outer.id})
+	}
+}
@@ -0,0 +1,60 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// Throttle forwards an item of the input only if at least minGap has elapsed
+// since the previously forwarded item. Items arriving in between are dropped
+// (leading edge throttling), so the consumer always gets the first item of a
+// burst and then at most one item per minGap.
+//
+// Error items are never dropped and are forwarded immediately. They do not
+// influence the timing of the successful items.
+//
+// Use WithOnDrop to observe the dropped items and WithClock to control the
+// time in tests.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithClock
+//   - WithOnDrop
+//
+// Example:
+//
+//	var dropped atomic.Int64
+//	updates := Throttle(ctx, events, 100*time.Millisecond, WithOnDrop(func() {
+//	    dropped.Add(1)
+//	}))
+func Throttle[T any](ctx context.Context, in Sequence[T], minGap time.Duration, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Throttle", o, func() {
+		defer close(r)
+		var last time.Time
+		forwarded := false
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error == nil {
+				now := o.clock.Now()
+				if forwarded && now.Sub(last) < minGap {
+					if o.onDrop != nil {
+						o.onDrop()
+					}
+					continue
+				}
+				last, forwarded = now, true
+			}
+			if !send(ctx, r, item) {
+				return
+			}
+		}
+	})
+	return r
+}
@@ -0,0 +1,107 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is the error of a call of a function returned by ThrottleFunc
+// that was rejected because the action ran less than minGap ago. It is only
+// used with WithRejectThrottled.
+var ErrThrottled = errors.New("async: call throttled")
+
+// throttler is the state shared by the calls of a function returned by
+// ThrottleFunc
+type throttler[T any] struct {
+	minGap time.Duration
+	action func(ctx context.Context) (T, error)
+	opts   []Option
+	o      *options
+
+	mu      sync.Mutex
+	started time.Time
+	ran     bool
+	// running holds the calls waiting for the current execution, it is nil
+	// while the action is not executing
+	running []*waiter[T]
+	last    _Result[T]
+}
+
+// ThrottleFunc wraps the action so that it is executed at most once per
+// minGap: a call executes the action if the previous execution started at
+// least minGap ago and is no longer in progress. A call during the cool-down
+// does not execute the action, its Result resolves with the outcome of the
+// most recent execution, waiting for it if it is still in progress. With
+// WithRejectThrottled such a call resolves with ErrThrottled instead.
+//
+// The gap is measured with the clock set with WithClock, the default clock
+// uses the monotonic time and is not affected by changes of the wall clock.
+//
+// A call whose context is done while it waits for the execution resolves with
+// the error of the context, the execution goes on. The action is executed
+// with a context that carries the values of the context of the call that
+// started it, but is not cancelled with it.
+//
+// The returned function is safe for concurrent use. It supports the same
+// options as Do, which executes the action, plus WithClock and
+// WithRejectThrottled.
+//
+// Example:
+//
+//	refresh := ThrottleFunc(30*time.Second, func(ctx context.Context) (*Token, error) {
+//	    return auth.Refresh(ctx)
+//	})
+//	token := <-refresh(ctx)
+func ThrottleFunc[T any](minGap time.Duration, action func(ctx context.Context) (T, error), opts ...Option) func(ctx context.Context) Result[T] {
+	t := &throttler[T]{minGap: minGap, action: action, opts: opts, o: newOptions(opts)}
+	return t.call
+}
+
+func (t *throttler[T]) call(ctx context.Context) Result[T] {
+	if ctx.Err() != nil {
+		return resolved(Fail[T](ctxError(ctx)))
+	}
+	t.mu.Lock()
+	now := t.o.clock.Now()
+	due := !t.ran || now.Sub(t.started) >= t.minGap
+	start := due && t.running == nil
+	switch {
+	case start:
+		t.started, t.ran = now, true
+		t.running = []*waiter[T]{}
+	case !due && t.o.rejectThrottled:
+		t.mu.Unlock()
+		return resolved(Fail[T](ErrThrottled))
+	case t.running == nil:
+		last := t.last
+		t.mu.Unlock()
+		return resolved(last)
+	}
+	w := &waiter[T]{r: make(Result[T], 1)}
+	w.stop = context.AfterFunc(ctx, func() {
+		w.resolve(Fail[T](ctxError(ctx)))
+	})
+	t.running = append(t.running, w)
+	t.mu.Unlock()
+	if start {
+		actx := context.WithoutCancel(ctx)
+		spawn("ThrottleFunc", t.o, func() {
+			t.settle(<-Do(actx, t.action, t.opts...))
+		})
+	}
+	return w.r
+}
+
+// settle records the outcome of an execution and resolves its calls
+func (t *throttler[T]) settle(item _Result[T]) {
+	t.mu.Lock()
+	waiters := t.running
+	t.running, t.last = nil, item
+	t.mu.Unlock()
+	for _, w := range waiters {
+		w.resolve(item)
+		w.stop()
+	}
+}
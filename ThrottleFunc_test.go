@@ -0,0 +1,78 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+func TestThrottleFuncLeading(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	var n atomic.Int64
+	refresh := async.ThrottleFunc(time.Minute, executions(&n), async.WithClock(clock))
+
+	// the first call executes right away, the calls of the cool-down share
+	// its outcome
+	if item := <-refresh(ctx); item.Value != 1 {
+		t.Fatalf("want the leading call executed, got %v", item)
+	}
+	clock.Advance(time.Minute - time.Millisecond)
+	if item := <-refresh(ctx); item.Value != 1 || n.Load() != 1 {
+		t.Fatalf("want the last outcome during the cool-down, got %v", item)
+	}
+	clock.Advance(time.Millisecond)
+	if item := <-refresh(ctx); item.Value != 2 {
+		t.Fatalf("want an execution after the gap, got %v", item)
+	}
+}
+
+func TestThrottleFuncRejected(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	var n atomic.Int64
+	refresh := async.ThrottleFunc(time.Minute, executions(&n), async.WithClock(clock), async.WithRejectThrottled())
+	<-refresh(ctx)
+	if item := <-refresh(ctx); !errors.Is(item.Error, async.ErrThrottled) {
+		t.Fatalf("want the call of the cool-down rejected, got %v", item)
+	}
+	clock.Advance(time.Minute)
+	if item := <-refresh(ctx); item.Value != 2 {
+		t.Fatalf("want an execution after the gap, got %v", item)
+	}
+}
+
+func TestThrottleFuncWaitsForExecution(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	release := make(chan struct{})
+	var n atomic.Int64
+	refresh := async.ThrottleFunc(time.Minute, func(ctx context.Context) (int64, error) {
+		<-release
+		return n.Add(1), nil
+	}, async.WithClock(clock))
+
+	first := refresh(ctx)
+	cancelled, cancel := context.WithCancel(ctx)
+	gone, waiting := refresh(cancelled), refresh(ctx)
+
+	// a cancelled call stops waiting, the execution goes on
+	cancel()
+	if item := <-gone; !errors.Is(item.Error, context.Canceled) {
+		t.Fatalf("want the cancelled call to fail, got %v", item)
+	}
+	if !pending(waiting) {
+		t.Fatal("want the call to wait for the execution in progress")
+	}
+	close(release)
+	for _, r := range []async.Result[int64]{first, waiting} {
+		if item := <-r; item.Value != 1 {
+			t.Fatalf("want the calls to share the execution, got %v", item)
+		}
+	}
+}
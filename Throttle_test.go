@@ -0,0 +1,62 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// processed sends an error item to in and receives it from out. Error items
+// pass through right away, so receiving it guarantees that the items sent
+// before were processed.
+func processed(t *testing.T, in, out async.Sequence[int]) {
+	t.Helper()
+	in <- async.Fail[int](errDownstream)
+	if item := <-out; !errors.Is(item.Error, errDownstream) {
+		t.Fatalf("want the error item passed through, got %v", item)
+	}
+}
+
+// throttled sends v to the throttle and reports whether it was forwarded
+func throttled(in, out async.Sequence[int], v int) bool {
+	go func() {
+		in <- async.Success(v)
+		in <- async.Fail[int](errDownstream)
+	}()
+	forwarded := false
+	for item := range out {
+		if item.Error != nil {
+			break
+		}
+		forwarded = item.Value == v
+	}
+	return forwarded
+}
+
+func TestThrottleDropsWithinGap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := fakeclock.New(time.Unix(0, 0))
+	drops := 0
+	in := make(async.Sequence[int])
+	out := async.Throttle(ctx, in, 100*time.Millisecond, async.WithClock(clock), async.WithOnDrop(func() { drops++ }))
+
+	var forwarded []int
+	start := clock.Now()
+	for i, at := range []time.Duration{0, 50, 99, 100, 150, 250} {
+		clock.Advance(start.Add(at * time.Millisecond).Sub(clock.Now()))
+		if throttled(in, out, i) {
+			forwarded = append(forwarded, i)
+		}
+	}
+	// the gap is measured from the last forwarded item, not the last one
+	// received
+	if !slices.Equal(forwarded, []int{0, 3, 5}) || drops != 3 {
+		t.Fatalf("want the items at 0, 100 and 250ms forwarded and 3 dropped, got %v and %d drops", forwarded, drops)
+	}
+}
@@ -0,0 +1,96 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// TimeoutEach transforms every item of the input with the given function,
+// where every invocation gets its own context with a timeout of d.
+//
+// If an invocation does not return within d, an error item carrying
+// context.DeadlineExceeded, wrapped into an *IndexedError, is emitted and
+// the stage continues with the next item. The overrunning invocation keeps
+// running in its own goroutine until it returns, its result is discarded. So
+// a runaway function never blocks the following items, while the stage still
+// emits at most one output at a time in input order.
+//
+// Errors returned by f and error items of the input are forwarded wrapped
+// with the index of the item. Every item of the input, including error items,
+// advances the index.
+//
+// The returned sequence is closed when the input is closed or the context is
+// done.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	responses := TimeoutEach(ctx, urls, 5*time.Second, func(ctx context.Context, url string) (*http.Response, error) {
+//	    req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return http.DefaultClient.Do(req)
+//	})
+func TimeoutEach[T, U any](ctx context.Context, in Sequence[T], d time.Duration, f func(ctx context.Context, v T) (U, error), opts ...Option) Sequence[U] {
+	o := newOptions(opts)
+	r := make(Sequence[U])
+	spawn("TimeoutEach", o, func() {
+		defer close(r)
+		for index := 0; ; index++ {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			if item.Error != nil {
+				if !send(ctx, r, Fail[U](indexInputError(index, item.Error))) {
+					return
+				}
+				continue
+			}
+			out, ok := timeoutOne(ctx, o, d, item.Value, f)
+			if !ok {
+				return
+			}
+			if out.Error != nil {
+				out.Error = indexError(index, out.Error)
+			}
+			if !send(ctx, r, out) {
+				return
+			}
+		}
+	})
+	return r
+}
+
+// timeoutOne runs f for a single value with a timeout. It reports false if the
+// parent context is done before f returned.
+func timeoutOne[T, U any](ctx context.Context, o *options, d time.Duration, v T, f func(ctx context.Context, v T) (U, error)) (_Result[U], bool) {
+	ictx, cancel := withTimeout(ctx, o.clock, d)
+	defer cancel()
+	done := make(chan _Result[U], 1)
+	spawn("TimeoutEach.f", o, func() {
+		value, err := f(ictx, v)
+		if err != nil {
+			done <- Fail[U](err)
+		} else {
+			done <- Success(value)
+		}
+	})
+	select {
+	case out := <-done:
+		return out, true
+	case <-ictx.Done():
+		select {
+		case out := <-done:
+			return out, true
+		default:
+		}
+		if ctx.Err() != nil {
+			return _Result[U]{}, false
+		}
+		return Fail[U](context.DeadlineExceeded), true
+	}
+}
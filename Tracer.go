@@ -0,0 +1,29 @@
+package async
+
+import (
+	"context"
+)
+
+// Tracer creates spans around async tasks. Start is called when a task
+// begins and returns the context the task runs with, so the span can be
+// injected, and a function that ends the span with the final error of the
+// task. An error of nil means success.
+//
+// The interface is intentionally small so that an adapter for a tracing
+// library like OpenTelemetry is a few lines:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, func(error)) {
+//	    ctx, span := o.t.Start(ctx, name)
+//	    return ctx, func(err error) {
+//	        if err != nil {
+//	            span.RecordError(err)
+//	            span.SetStatus(codes.Error, err.Error())
+//	        }
+//	        span.End()
+//	    }
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, func(err error))
+}
@@ -0,0 +1,106 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	async "github.com/uoul/go-async"
+)
+
+type spanKey struct{}
+
+// span is a span recorded by recordingTracer
+type span struct {
+	name string
+	err  error
+	// parent is the name of the span active when this one started
+	parent string
+}
+
+// recordingTracer records the ended spans
+type recordingTracer struct {
+	mu    sync.Mutex
+	ended []span
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, func(err error)) {
+	s := span{name: name}
+	if parent, ok := ctx.Value(spanKey{}).(string); ok {
+		s.parent = parent
+	}
+	return context.WithValue(ctx, spanKey{}, name), func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s.err = err
+		r.ended = append(r.ended, s)
+	}
+}
+
+func (r *recordingTracer) spans() []span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.ended)
+}
+
+func TestTracerDo(t *testing.T) {
+	tracer := &recordingTracer{}
+	errFailed := errors.New("failed")
+	r := <-async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		if ctx.Value(spanKey{}) != "fetch" {
+			t.Error("want the action to run in the context of the span")
+		}
+		inner := <-async.Do(ctx, func(ctx context.Context) (int, error) {
+			return 1, nil
+		}, async.WithTracer(tracer))
+		return inner.Value, errFailed
+	}, async.WithTracer(tracer), async.WithName("fetch"))
+	if !errors.Is(r.Error, errFailed) {
+		t.Fatalf("want the error of the action, got %v", r.Error)
+	}
+	spans := tracer.spans()
+	if len(spans) != 2 {
+		t.Fatalf("want two spans, got %v", spans)
+	}
+	if inner := spans[0]; inner.name != "async.Do" || inner.parent != "fetch" || inner.err != nil {
+		t.Fatalf("want an unnamed successful child span of fetch, got %+v", inner)
+	}
+	if outer := spans[1]; outer.name != "fetch" || outer.parent != "" || !errors.Is(outer.err, errFailed) {
+		t.Fatalf("want the failed fetch span, got %+v", outer)
+	}
+}
+
+func TestTracerStream(t *testing.T) {
+	tracer := &recordingTracer{}
+	for range async.Stream(context.Background(), counter(), async.WithTracer(tracer), async.WithMaxIterations(3)) {
+	}
+	spans := tracer.spans()
+	if len(spans) != 1 || spans[0].name != "async.Stream" || !errors.Is(spans[0].err, async.ErrMaxIterations) {
+		t.Fatalf("want a single span ended with the last error, got %+v", spans)
+	}
+}
+
+func TestTracerSpanPerStep(t *testing.T) {
+	tracer := &recordingTracer{}
+	errStep := errors.New("step failed")
+	n := 0
+	for range async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		n++
+		if n == 2 {
+			return 0, errStep, true
+		}
+		return n, nil, n < 3
+	}, async.WithTracer(tracer), async.WithSpanPerStep(), async.WithName("poll")) {
+	}
+	spans := tracer.spans()
+	if len(spans) != 3 {
+		t.Fatalf("want a span per step, got %+v", spans)
+	}
+	for i, s := range spans {
+		if s.name != "poll" || (i == 1) != errors.Is(s.err, errStep) {
+			t.Fatalf("want step %d recorded with its error, got %+v", i+1, s)
+		}
+	}
+}
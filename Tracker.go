@@ -0,0 +1,148 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrShutdown is the cause of the cancellation of the tasks of a Tracker that
+// was shut down, see CancelError.
+var ErrShutdown = errors.New("async: tracker shut down")
+
+// Tracker counts the Do and Stream tasks started with WithTracker, so that a
+// process can wait for its outstanding async work before it exits. It is safe
+// for concurrent use. Create it with NewTracker.
+//
+// Example:
+//
+//	tracker := NewTracker()
+//	Do(ctx, sendReport, WithTracker(tracker))
+//	...
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := tracker.Shutdown(ctx); err != nil {
+//	    log.Printf("shutdown incomplete: %v", err)
+//	}
+type Tracker struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu      sync.Mutex
+	lastID  uint64
+	running map[uint64]string
+	// idle is closed when the last running task finished
+	idle chan struct{}
+}
+
+// PendingTasksError is the error of Tracker.Wait and Tracker.Shutdown if the
+// context is done before all tasks finished. It lists the tasks still
+// running, by the name given with WithName or by kind if unnamed.
+type PendingTasksError struct {
+	Tasks []string
+	Err   error
+}
+
+func (e *PendingTasksError) Error() string {
+	return fmt.Sprintf("async: %d tasks still running (%s): %v", len(e.Tasks), strings.Join(e.Tasks, ", "), e.Err)
+}
+
+// Unwrap returns the error of the context
+func (e *PendingTasksError) Unwrap() error {
+	return e.Err
+}
+
+// NewTracker creates a tracker without tasks
+func NewTracker() *Tracker {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Tracker{ctx: ctx, cancel: cancel, running: map[uint64]string{}}
+}
+
+// Running returns the names of the tasks still running, sorted
+func (t *Tracker) Running() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.running))
+	for _, name := range t.running {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Wait blocks until all tracked tasks finished. If the context is done
+// first, it returns a *PendingTasksError.
+func (t *Tracker) Wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		if len(t.running) == 0 {
+			t.mu.Unlock()
+			return nil
+		}
+		if t.idle == nil {
+			t.idle = make(chan struct{})
+		}
+		idle := t.idle
+		t.mu.Unlock()
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return &PendingTasksError{Tasks: t.Running(), Err: ctxError(ctx)}
+		}
+	}
+}
+
+// Shutdown cancels the contexts of all tracked tasks, with ErrShutdown as
+// cause, and waits for them like Wait. Tasks started with the tracker
+// afterwards begin with a cancelled context.
+func (t *Tracker) Shutdown(ctx context.Context) error {
+	t.cancel(ErrShutdown)
+	return t.Wait(ctx)
+}
+
+// add registers a task that is about to start. It is safe to call on a nil
+// tracker.
+func (t *Tracker) add(name string) uint64 {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastID++
+	t.running[t.lastID] = name
+	return t.lastID
+}
+
+// remove unregisters a finished task. It is safe to call on a nil tracker.
+func (t *Tracker) remove(id uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.running, id)
+	if len(t.running) == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// derive returns a context of a task that is also cancelled on Shutdown
+func (t *Tracker) derive(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if t.ctx.Err() != nil {
+		// AfterFunc would cancel asynchronously, after the task started
+		cancel(context.Cause(t.ctx))
+		return ctx, func() {}
+	}
+	stop := context.AfterFunc(t.ctx, func() {
+		cancel(context.Cause(t.ctx))
+	})
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
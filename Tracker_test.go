@@ -0,0 +1,91 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+func TestTrackerNestedTasks(t *testing.T) {
+	ctx := context.Background()
+	tracker := async.NewTracker()
+	release := make(chan struct{})
+	type ids struct{ parent, child, childParent string }
+	r := async.Do(ctx, func(ctx context.Context) (ids, error) {
+		child := <-async.Do(ctx, func(ctx context.Context) (ids, error) {
+			<-release
+			return ids{child: async.TaskID(ctx), childParent: async.ParentTaskID(ctx)}, nil
+		}, async.WithTracker(tracker), async.WithName("child"))
+		child.Value.parent = async.TaskID(ctx)
+		return child.Value, child.Error
+	}, async.WithTracker(tracker), async.WithName("parent"))
+
+	eventually(t, "both tasks are tracked", func() bool {
+		return slices.Equal(tracker.Running(), []string{"child", "parent"})
+	})
+	close(release)
+	item := <-r
+	if item.Error != nil {
+		t.Fatalf("want no error, got %v", item.Error)
+	}
+	got := item.Value
+	if got.parent == "" || got.child == "" || got.parent == got.child {
+		t.Fatalf("want distinct task IDs in the tracked contexts, got %+v", got)
+	}
+	if got.childParent != got.parent {
+		t.Fatalf("want the parent ID %q in the child, got %q", got.parent, got.childParent)
+	}
+	if err := tracker.Wait(ctx); err != nil {
+		t.Fatalf("want no pending tasks, got %v", err)
+	}
+}
+
+func TestTrackerWaitTimeout(t *testing.T) {
+	tracker := async.NewTracker()
+	release := make(chan struct{})
+	defer close(release)
+	async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		<-release
+		return 0, nil
+	}, async.WithTracker(tracker), async.WithName("report"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := tracker.Wait(ctx)
+	var pending *async.PendingTasksError
+	if !errors.As(err, &pending) || !slices.Equal(pending.Tasks, []string{"report"}) {
+		t.Fatalf("want the report task pending, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want the context error wrapped, got %v", err)
+	}
+}
+
+func TestTrackerShutdown(t *testing.T) {
+	ctx := context.Background()
+	tracker := async.NewTracker()
+	started := make(chan struct{})
+	r := async.Do(ctx, func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, async.WithTracker(tracker))
+	<-started
+
+	if err := tracker.Shutdown(ctx); err != nil {
+		t.Fatalf("want all tasks finished, got %v", err)
+	}
+	if item := <-r; !errors.Is(item.Error, async.ErrShutdown) || !errors.Is(item.Error, context.Canceled) {
+		t.Fatalf("want the task cancelled with ErrShutdown, got %v", item.Error)
+	}
+	late := <-async.Do(ctx, func(ctx context.Context) (int, error) {
+		return 0, ctx.Err()
+	}, async.WithTracker(tracker))
+	if !errors.Is(late.Error, async.ErrShutdown) {
+		t.Fatalf("want a task started after Shutdown cancelled, got %v", late.Error)
+	}
+}
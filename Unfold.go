@@ -0,0 +1,44 @@
+package async
+
+import (
+	"context"
+)
+
+// Unfold executes the given function repeatedly in a goroutine, threading an
+// explicit state value from one iteration to the next, and returns a
+// Sequence[T] channel that receives the produced results.
+//
+// The first call of f receives the initial state. Every call returns the state
+// to use for the next call, a result of type T, an error, and a boolean
+// indicating whether to continue (true) or stop (false). This removes the need
+// to close over mutable variables as it is required with Stream.
+//
+// The error and continue semantics are the same as for Stream:
+//   - If f returns an error, a _Result[T] with a non-nil Error field is sent
+//   - If f succeeds, a _Result[T] with the result in the Value field is sent
+//   - If more is false, the loop terminates and the channel closes
+//
+// Example (cursor based pagination):
+//
+//	seq := Unfold(ctx, "", func(ctx context.Context, cursor string) (string, []Item, error, bool) {
+//	    page, err := client.List(ctx, cursor)
+//	    if err != nil {
+//	        return cursor, nil, err, false
+//	    }
+//	    return page.NextCursor, page.Items, nil, page.NextCursor != ""
+//	})
+//	for result := range seq {
+//	    if result.Error != nil {
+//	        log.Printf("error: %v", result.Error)
+//	        continue
+//	    }
+//	    log.Printf("received page with %d items", len(result.Value))
+//	}
+func Unfold[S, T any](ctx context.Context, initial S, f func(ctx context.Context, state S) (next S, value T, err error, more bool)) Sequence[T] {
+	state := initial
+	return Stream(ctx, func(ctx context.Context) (T, error, bool) {
+		next, value, err, more := f(ctx, state)
+		state = next
+		return value, err, more
+	})
+}
@@ -0,0 +1,89 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// fakePage is a page of a fake cursor paginated API
+type fakePage struct {
+	Items      []string
+	NextCursor string
+}
+
+// fakeAPI serves the pages keyed by their cursor
+var fakeAPI = map[string]fakePage{
+	"":   {Items: []string{"ada", "alan"}, NextCursor: "c2"},
+	"c2": {Items: []string{"barbara", "donald"}, NextCursor: "c3"},
+	"c3": {Items: []string{"edsger"}},
+}
+
+func listUsers(ctx context.Context, cursor string) (fakePage, error) {
+	page, ok := fakeAPI[cursor]
+	if !ok {
+		return fakePage{}, fmt.Errorf("unknown cursor %q", cursor)
+	}
+	return page, nil
+}
+
+func ExampleUnfold() {
+	ctx := context.Background()
+	pages := async.Unfold(ctx, "", func(ctx context.Context, cursor string) (string, []string, error, bool) {
+		page, err := listUsers(ctx, cursor)
+		if err != nil {
+			return cursor, nil, err, false
+		}
+		return page.NextCursor, page.Items, nil, page.NextCursor != ""
+	})
+	for result := range pages {
+		if result.Error != nil {
+			fmt.Println("error:", result.Error)
+			continue
+		}
+		fmt.Println(result.Value)
+	}
+	// Output:
+	// [ada alan]
+	// [barbara donald]
+	// [edsger]
+}
+
+func TestUnfoldThreadsState(t *testing.T) {
+	ctx := context.Background()
+	var states []int
+	seq := async.Unfold(ctx, 1, func(ctx context.Context, n int) (int, int, error, bool) {
+		states = append(states, n)
+		return n * 2, n, nil, n < 8
+	})
+	asynctest.ExpectValues(t, ctx, seq, []int{1, 2, 4, 8})
+	if fmt.Sprint(states) != "[1 2 4 8]" {
+		t.Fatalf("want the evolved states passed on, got %v", states)
+	}
+}
+
+func TestUnfoldContinuesAfterErrors(t *testing.T) {
+	ctx := context.Background()
+	var errs int
+	seq := async.Unfold(ctx, 0, func(ctx context.Context, n int) (int, int, error, bool) {
+		if n == 1 {
+			return n + 1, 0, errDownstream, true
+		}
+		return n + 1, n, nil, n < 3
+	})
+	var got []int
+	for result := range seq {
+		if errors.Is(result.Error, errDownstream) {
+			errs++
+			continue
+		}
+		got = append(got, result.Value)
+	}
+	if fmt.Sprint(got) != "[0 2 3]" || errs != 1 {
+		t.Fatalf("want values [0 2 3] and one error, got %v and %d errors", got, errs)
+	}
+}
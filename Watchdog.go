@@ -0,0 +1,59 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStreamStalled is wrapped by the error item Watchdog emits when its input
+// produced no item for too long.
+var ErrStreamStalled = errors.New("async: sequence stalled")
+
+// Watchdog forwards the items of the input, but if maxIdle passes without an
+// item from the input, it emits an error item wrapping ErrStreamStalled and
+// closes the returned sequence. This turns a silent hang of a pipeline into
+// an explicit error.
+//
+// The idle time is measured while the stage waits for the input and restarts
+// after every item, error items included. Time spent waiting for the consumer
+// to receive an item does not count.
+//
+// The returned sequence is closed when the input is closed, the input stalled
+// or the context is done.
+//
+// Supported options:
+//   - WithClock
+//
+// Example:
+//
+//	for result := range Watchdog(ctx, ticks, 30*time.Second) {
+//	    if errors.Is(result.Error, ErrStreamStalled) {
+//	        log.Print("no tick for 30 seconds, reconnecting")
+//	    }
+//	}
+func Watchdog[T any](ctx context.Context, in Sequence[T], maxIdle time.Duration, opts ...Option) Sequence[T] {
+	o := newOptions(opts)
+	r := make(Sequence[T])
+	spawn("Watchdog", o, func() {
+		defer close(r)
+		t := o.clock.NewTimer(maxIdle)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C():
+				send(ctx, r, Fail[T](fmt.Errorf("%w: no item within %v", ErrStreamStalled, maxIdle)))
+				return
+			case item, ok := <-in:
+				if !ok || !send(ctx, r, item) {
+					return
+				}
+				t.Reset(maxIdle)
+			}
+		}
+	})
+	return r
+}
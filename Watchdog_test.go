@@ -0,0 +1,57 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+	"github.com/uoul/go-async/asynctest/fakeclock"
+)
+
+// fed sends v to the watchdog twice at the current time. Once the second
+// item was taken, the idle timer was restarted after the first one, so the
+// timer is due maxIdle after the current time either way.
+func fed(t *testing.T, in, out async.Sequence[int], v int) {
+	t.Helper()
+	for range 2 {
+		in <- async.Success(v)
+		if item := <-out; item.Value != v {
+			t.Fatalf("want the item forwarded, got %v", item)
+		}
+	}
+}
+
+func TestWatchdogFires(t *testing.T) {
+	asynctest.VerifyNoLeaks(t)
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	in := make(async.Sequence[int])
+	out := async.Watchdog(ctx, in, 10*time.Second, async.WithClock(clock))
+	clock.BlockUntil(1)
+
+	// every item restarts the idle time
+	for i := range 3 {
+		clock.Advance(9 * time.Second)
+		fed(t, in, out, i)
+	}
+	clock.Advance(10*time.Second - time.Nanosecond)
+	select {
+	case item := <-out:
+		t.Fatalf("want no item before the input was idle for 10s, got %v", item)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Nanosecond)
+	if item := <-out; !errors.Is(item.Error, async.ErrStreamStalled) {
+		t.Fatalf("want the stall reported, got %v", item)
+	}
+	asynctest.Drained(t, ctx, out)
+}
+
+func TestWatchdogForwardsUntilClosed(t *testing.T) {
+	ctx := context.Background()
+	clock := fakeclock.New(time.Unix(0, 0))
+	asynctest.ExpectValues(t, ctx, async.Watchdog(ctx, seqOf(1, 2, 3), time.Second, async.WithClock(clock)), []int{1, 2, 3})
+}
@@ -0,0 +1,72 @@
+// Package asynctest provides helpers to test code built on the async package.
+package asynctest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/internal/registry"
+)
+
+// LeakTimeout is the time VerifyNoLeaks grants the goroutines of the async
+// package to finish at the end of a test before they are reported as leaked.
+var LeakTimeout = time.Second
+
+// VerifyNoLeaks fails the test if goroutines started by the async package
+// during the test are still running at its end. Every leaked goroutine is
+// reported with the function that started it, its task name, what it is
+// doing and where it was created, e.g.
+//
+//	Stream "pager" started at pager.go:42 is still blocked on send
+//
+// Call it at the beginning of the test. Only goroutines started after the
+// call are considered. Tests using VerifyNoLeaks should not run in parallel
+// with other tests using the async package, whose goroutines would be
+// reported as well.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	mark, disable := registry.Enable()
+	t.Cleanup(func() {
+		defer disable()
+		deadline := time.Now().Add(LeakTimeout)
+		leaked := registry.Since(mark)
+		for len(leaked) > 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			leaked = registry.Since(mark)
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d goroutine(s) of the async package leaked:", len(leaked))
+		for _, e := range leaked {
+			name := ""
+			if e.Name != "" {
+				name = fmt.Sprintf(" %q", e.Name)
+			}
+			fmt.Fprintf(&b, "\n\n%s%s started at %s is still %s\ncreated by:\n%s", e.Kind, name, e.Origin(), e.State(), e.Stack())
+		}
+		t.Error(b.String())
+	})
+}
+
+// SynchronousRunner is an async.Runner that executes every function inline on
+// the calling goroutine instead of starting a new one. With it, Do and the
+// functions built on it have settled when they return, which allows to unit
+// test call sites without real concurrency:
+//
+//	r := <-async.Do(ctx, action, async.WithRunner(asynctest.SynchronousRunner{}))
+//
+// It must not be used for Stream and the Sequence stages, which block
+// delivering their first item until it is received and would deadlock.
+type SynchronousRunner struct{}
+
+var _ async.Runner = SynchronousRunner{}
+
+// Go executes fn inline
+func (SynchronousRunner) Go(fn func()) {
+	fn()
+}
@@ -0,0 +1,119 @@
+package asynctest_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+// fakeTB records the failures of a helper under test instead of failing the
+// test running it
+type fakeTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Error(args ...any) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// end runs the cleanups like the end of a test does
+func (f *fakeTB) end() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+// withLeakTimeout shortens LeakTimeout for the duration of the test
+func withLeakTimeout(t *testing.T, d time.Duration) {
+	old := asynctest.LeakTimeout
+	asynctest.LeakTimeout = d
+	t.Cleanup(func() {
+		asynctest.LeakTimeout = old
+	})
+}
+
+func TestVerifyNoLeaksReportsLeak(t *testing.T) {
+	withLeakTimeout(t, 50*time.Millisecond)
+	tb := &fakeTB{}
+	asynctest.VerifyNoLeaks(tb)
+	seq := async.Stream(context.Background(), func(ctx context.Context) (int, error, bool) {
+		return 1, nil, false
+	}, async.WithName("leaky"))
+	tb.end()
+	// release the stream blocked on its only item
+	for range seq {
+	}
+
+	if len(tb.errors) != 1 {
+		t.Fatalf("want the leak reported, got %q", tb.errors)
+	}
+	report := tb.errors[0]
+	for _, want := range []string{"1 goroutine(s) of the async package leaked", `Stream "leaky" started at `, "/asynctest_test.go:", "is still blocked on send"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("want %q in the report, got:\n%s", want, report)
+		}
+	}
+}
+
+func TestVerifyNoLeaksFinished(t *testing.T) {
+	withLeakTimeout(t, 50*time.Millisecond)
+	release := make(chan struct{})
+	// started before VerifyNoLeaks, so it is not considered
+	before := async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		<-release
+		return 0, nil
+	})
+	defer func() {
+		close(release)
+		<-before
+	}()
+
+	tb := &fakeTB{}
+	asynctest.VerifyNoLeaks(tb)
+	// finishes only after the end of the test, within LeakTimeout
+	late := async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 0, nil
+	})
+	tb.end()
+	<-late
+	if len(tb.errors) != 0 {
+		t.Fatalf("want no leak reported, got %q", tb.errors)
+	}
+}
+
+func TestSynchronousRunner(t *testing.T) {
+	ran := false
+	r := async.Do(context.Background(), func(ctx context.Context) (int, error) {
+		ran = true
+		return 1, nil
+	}, async.WithRunner(asynctest.SynchronousRunner{}))
+	if !ran {
+		t.Fatal("want the action executed before Do returned")
+	}
+	select {
+	case item := <-r:
+		if item.Value != 1 || item.Error != nil {
+			t.Fatalf("want the value of the action, got %v", item)
+		}
+	default:
+		t.Fatal("want the result settled when Do returned")
+	}
+}
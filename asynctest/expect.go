@@ -0,0 +1,144 @@
+package asynctest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// ExpectTimeout bounds ExpectValues, ExpectError and Drained if the given
+// context has no deadline, so that they never hang a test.
+var ExpectTimeout = 5 * time.Second
+
+// bounded returns the context with a deadline of ExpectTimeout, unless it
+// has a deadline already
+func bounded(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, ExpectTimeout)
+}
+
+// received records the items drained from a sequence for failure messages
+type received[T any] struct {
+	values []T
+	errs   []error
+	// open is true if the sequence was not closed in time
+	open bool
+}
+
+// drain receives the items of seq until it is closed or the context is done
+func drain[T any](ctx context.Context, seq async.Sequence[T]) received[T] {
+	var r received[T]
+	for {
+		select {
+		case item, ok := <-seq:
+			if !ok {
+				return r
+			}
+			if item.Error != nil {
+				r.errs = append(r.errs, item.Error)
+			} else {
+				r.values = append(r.values, item.Value)
+			}
+		case <-ctx.Done():
+			r.open = true
+			return r
+		}
+	}
+}
+
+func (r received[T]) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "received values %v", r.values)
+	if len(r.errs) > 0 {
+		fmt.Fprintf(&b, " and errors %v", r.errs)
+	}
+	if r.open {
+		b.WriteString(", the sequence is still open")
+	} else {
+		b.WriteString(", the sequence is closed")
+	}
+	return b.String()
+}
+
+// ExpectValues drains the sequence and fails the test unless its successful
+// values equal want, compared with reflect.DeepEqual, and it was closed in
+// time. Error items are not compared, but reported on failure. The sequence
+// is drained until the deadline of the context or ExpectTimeout.
+//
+// Example:
+//
+//	asynctest.ExpectValues(t, ctx, async.Take(ctx, seq, 3), []int{1, 2, 3})
+func ExpectValues[T any](t testing.TB, ctx context.Context, seq async.Sequence[T], want []T) {
+	t.Helper()
+	ctx, cancel := bounded(ctx)
+	defer cancel()
+	got := drain(ctx, seq)
+	switch {
+	case !reflect.DeepEqual(got.values, want) && !(len(got.values) == 0 && len(want) == 0):
+		t.Errorf("ExpectValues: want values %v, %v", want, got)
+	case got.open:
+		t.Errorf("ExpectValues: sequence not closed within the timeout, %v", got)
+	}
+}
+
+// ExpectError receives the Result and fails the test unless it resolves with
+// an error matching wantErr, see errors.Is, in time. A nil wantErr expects a
+// success. The Result is awaited until the deadline of the context or
+// ExpectTimeout.
+//
+// Example:
+//
+//	asynctest.ExpectError(t, ctx, async.Do(ctx, fetch), ErrNotFound)
+func ExpectError[T any](t testing.TB, ctx context.Context, r async.Result[T], wantErr error) {
+	t.Helper()
+	ctx, cancel := bounded(ctx)
+	defer cancel()
+	select {
+	case item, ok := <-r:
+		switch {
+		case !ok:
+			t.Errorf("ExpectError: want %s, the result is closed without a value", expected(wantErr))
+		case wantErr == nil && item.Error != nil:
+			t.Errorf("ExpectError: want success, got error %v", item.Error)
+		case wantErr != nil && item.Error == nil:
+			t.Errorf("ExpectError: want error %v, got value %v", wantErr, item.Value)
+		case !errors.Is(item.Error, wantErr):
+			t.Errorf("ExpectError: want error %v, got error %v", wantErr, item.Error)
+		}
+	case <-ctx.Done():
+		t.Errorf("ExpectError: want %s, the result is still pending after the timeout", expected(wantErr))
+	}
+}
+
+// expected describes the outcome ExpectError waits for
+func expected(wantErr error) string {
+	if wantErr == nil {
+		return "success"
+	}
+	return fmt.Sprintf("error %v", wantErr)
+}
+
+// Drained fails the test unless the sequence is closed in time. The items
+// received until then are discarded. The sequence is drained until the
+// deadline of the context or ExpectTimeout.
+//
+// Example:
+//
+//	cancel()
+//	asynctest.Drained(t, context.Background(), seq)
+func Drained[T any](t testing.TB, ctx context.Context, seq async.Sequence[T]) {
+	t.Helper()
+	ctx, cancel := bounded(ctx)
+	defer cancel()
+	if got := drain(ctx, seq); got.open {
+		t.Errorf("Drained: sequence not closed within the timeout, %v", got)
+	}
+}
@@ -0,0 +1,130 @@
+package asynctest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	async "github.com/uoul/go-async"
+	"github.com/uoul/go-async/asynctest"
+)
+
+var errExpected = errors.New("expected")
+
+// sequence returns a sequence of the given items, closed unless open is set
+func sequence(open bool, items ...async.Sequence[int]) async.Sequence[int] {
+	s := make(async.Sequence[int], len(items))
+	for _, item := range items {
+		s <- <-item
+	}
+	if !open {
+		close(s)
+	}
+	return s
+}
+
+// value returns a sequence holding a single value
+func value(v int) async.Sequence[int] {
+	s := make(async.Sequence[int], 1)
+	s <- async.Success(v)
+	return s
+}
+
+// failure returns a sequence holding a single error item
+func failure(err error) async.Sequence[int] {
+	s := make(async.Sequence[int], 1)
+	s <- async.Fail[int](err)
+	return s
+}
+
+// result returns a Result with the given item, closed without one if item is
+// nil, and pending if pending is set
+func result(item async.Sequence[int], pending bool) async.Result[int] {
+	r := make(async.Result[int], 1)
+	if item != nil {
+		r <- <-item
+	}
+	if !pending {
+		close(r)
+	}
+	return r
+}
+
+// expectReport fails unless the fake test recorded a single failure
+// containing want, or none if want is empty
+func expectReport(t *testing.T, tb *fakeTB, want string) {
+	t.Helper()
+	switch {
+	case want == "" && len(tb.errors) != 0:
+		t.Fatalf("want no failure, got %q", tb.errors)
+	case want == "":
+	case len(tb.errors) != 1 || !strings.Contains(tb.errors[0], want):
+		t.Fatalf("want a failure containing %q, got %q", want, tb.errors)
+	}
+}
+
+// short returns a context that bounds the helpers to a few milliseconds
+func short(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestExpectValues(t *testing.T) {
+	tests := []struct {
+		name string
+		seq  async.Sequence[int]
+		want []int
+		fail string
+	}{
+		{"equal", sequence(false, value(1), failure(errExpected), value(2)), []int{1, 2}, ""},
+		{"empty", sequence(false), nil, ""},
+		{"empty slice", sequence(false), []int{}, ""},
+		{"different", sequence(false, value(1), failure(errExpected)), []int{1, 2}, "want values [1 2], received values [1] and errors [expected], the sequence is closed"},
+		{"open", sequence(true, value(1)), []int{1}, "sequence not closed within the timeout, received values [1], the sequence is still open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{}
+			asynctest.ExpectValues(tb, short(t), tt.seq, tt.want)
+			expectReport(t, tb, tt.fail)
+		})
+	}
+}
+
+func TestExpectError(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       async.Result[int]
+		wantErr error
+		fail    string
+	}{
+		{"success", result(value(1), false), nil, ""},
+		{"error", result(failure(errExpected), false), errExpected, ""},
+		{"wrapped error", result(failure(errors.Join(errExpected)), false), errExpected, ""},
+		{"unexpected error", result(failure(errExpected), false), nil, "want success, got error expected"},
+		{"unexpected value", result(value(1), false), errExpected, "want error expected, got value 1"},
+		{"other error", result(failure(errors.New("other")), false), errExpected, "want error expected, got error other"},
+		{"closed", result(nil, false), errExpected, "want error expected, the result is closed without a value"},
+		{"pending", result(nil, true), nil, "want success, the result is still pending after the timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{}
+			asynctest.ExpectError(tb, short(t), tt.r, tt.wantErr)
+			expectReport(t, tb, tt.fail)
+		})
+	}
+}
+
+func TestDrained(t *testing.T) {
+	tb := &fakeTB{}
+	asynctest.Drained(tb, short(t), sequence(false, value(1), failure(errExpected)))
+	expectReport(t, tb, "")
+
+	tb = &fakeTB{}
+	asynctest.Drained(tb, short(t), sequence(true, value(1)))
+	expectReport(t, tb, "Drained: sequence not closed within the timeout, received values [1]")
+}
@@ -0,0 +1,211 @@
+// Package fakeclock provides an async.Clock whose time only moves when the
+// test advances it, so that time based functions of the async package can be
+// tested deterministically and without sleeping.
+package fakeclock
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	async "github.com/uoul/go-async"
+)
+
+// Clock is a manually advanced async.Clock. It is safe for concurrent use.
+type Clock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+var _ async.Clock = (*Clock)(nil)
+
+// waiter is a pending timer or ticker
+type waiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+// New creates a clock that starts at the given time
+func New(start time.Time) *Clock {
+	c := &Clock{now: start}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current fake time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the time forward by d and fires all timers and tickers that
+// become due, in the order of their deadlines. A ticker fires at most once per
+// period passed, ticks not received in time are dropped like with
+// time.Ticker.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for {
+		w := c.next()
+		if w == nil || w.deadline.After(target) {
+			break
+		}
+		c.now = w.deadline
+		c.fire(w)
+	}
+	c.now = target
+	c.changed.Broadcast()
+}
+
+// Waiters returns the number of timers, tickers and sleeps currently pending
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers, tickers or sleeps are pending.
+// This allows a test to advance the clock only after the code under test
+// started waiting.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+// NewTimer creates a timer that fires once the clock was advanced by d
+func (c *Clock) NewTimer(d time.Duration) async.Timer {
+	t := &timer{clock: c, w: &waiter{ch: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker creates a ticker that fires every time the clock was advanced by
+// another period d
+func (c *Clock) NewTicker(d time.Duration) async.Ticker {
+	if d <= 0 {
+		panic("fakeclock: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &waiter{deadline: c.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	c.add(w)
+	return &ticker{clock: c, w: w}
+}
+
+// Sleep blocks until the clock was advanced by d or the context is done
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := c.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// next returns the pending waiter with the earliest deadline
+func (c *Clock) next() *waiter {
+	if len(c.waiters) == 0 {
+		return nil
+	}
+	return slices.MinFunc(c.waiters, func(a, b *waiter) int {
+		return a.deadline.Compare(b.deadline)
+	})
+}
+
+// fire delivers the time to a due waiter and reschedules tickers
+func (c *Clock) fire(w *waiter) {
+	select {
+	case w.ch <- c.now:
+	default:
+	}
+	if w.period > 0 {
+		w.deadline = w.deadline.Add(w.period)
+		return
+	}
+	c.remove(w)
+}
+
+func (c *Clock) add(w *waiter) {
+	c.waiters = append(c.waiters, w)
+	c.changed.Broadcast()
+}
+
+// remove unschedules a waiter and reports whether it was pending
+func (c *Clock) remove(w *waiter) bool {
+	i := slices.Index(c.waiters, w)
+	if i < 0 {
+		return false
+	}
+	c.waiters = slices.Delete(c.waiters, i, i+1)
+	c.changed.Broadcast()
+	return true
+}
+
+type timer struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.w.ch
+}
+
+// Stop prevents the timer from firing. Like time.Timer since Go 1.23, a value
+// not received yet is discarded.
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.stop()
+}
+
+func (t *timer) stop() bool {
+	pending := t.clock.remove(t.w)
+	select {
+	case <-t.w.ch:
+	default:
+	}
+	return pending
+}
+
+// Reset changes the timer to fire once the clock was advanced by d
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	pending := t.stop()
+	t.w.deadline = t.clock.now.Add(d)
+	if d <= 0 {
+		t.w.ch <- t.clock.now
+		return pending
+	}
+	t.clock.add(t.w)
+	return pending
+}
+
+type ticker struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.w)
+}
@@ -0,0 +1,9 @@
+package async
+
+// SlotWaiters returns the number of actions waiting for a slot of the cap
+// set with SetMaxConcurrency
+func SlotWaiters() int {
+	concurrency.mu.Lock()
+	defer concurrency.mu.Unlock()
+	return len(concurrency.waiters)
+}
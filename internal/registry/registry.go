@@ -0,0 +1,146 @@
+// Package registry keeps track of the goroutines started by the async
+// package while at least one leak verification is active. When no
+// verification is active, registering a goroutine costs a single atomic load.
+package registry
+
+import (
+	"cmp"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rootPackage is the prefix of the functions of the async package itself,
+// which are skipped when looking for the creation site of a goroutine
+const rootPackage = "github.com/uoul/go-async."
+
+var (
+	verifications atomic.Int32
+	lastSeq       atomic.Uint64
+	mu            sync.Mutex
+	entries       = map[uint64]*Entry{}
+)
+
+// Entry is a registered goroutine
+type Entry struct {
+	// Seq is the registration sequence number of the goroutine
+	Seq uint64
+	// Kind is the function that started the goroutine, e.g. "Stream"
+	Kind string
+	// Name is the task name given with WithName, if any
+	Name string
+	// Started is the time the goroutine was started
+	Started time.Time
+
+	pcs   []uintptr
+	state atomic.Pointer[string]
+}
+
+// Enable activates the registration of goroutines until the returned function
+// is called. It returns the last sequence number registered before, so that
+// only goroutines registered afterwards can be inspected.
+func Enable() (mark uint64, disable func()) {
+	verifications.Add(1)
+	var once sync.Once
+	return lastSeq.Load(), func() {
+		once.Do(func() {
+			verifications.Add(-1)
+		})
+	}
+}
+
+// Register records a goroutine that is about to be started. It returns nil if
+// no verification is active. skip is the number of stack frames to skip, with
+// 0 identifying the caller of Register.
+func Register(kind, name string, skip int) *Entry {
+	if verifications.Load() == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip+2, pcs)
+	e := &Entry{
+		Seq:     lastSeq.Add(1),
+		Kind:    kind,
+		Name:    name,
+		Started: time.Now(),
+		pcs:     pcs[:n],
+	}
+	e.SetState("running")
+	mu.Lock()
+	entries[e.Seq] = e
+	mu.Unlock()
+	return e
+}
+
+// SetState records what the goroutine is currently doing, e.g. "blocked on
+// send". It is a no-op on a nil entry.
+func (e *Entry) SetState(state string) {
+	if e != nil {
+		s := state
+		e.state.Store(&s)
+	}
+}
+
+// State returns what the goroutine is currently doing
+func (e *Entry) State() string {
+	return *e.state.Load()
+}
+
+// Done removes the goroutine from the registry. It is a no-op on a nil entry.
+func (e *Entry) Done() {
+	if e == nil {
+		return
+	}
+	mu.Lock()
+	delete(entries, e.Seq)
+	mu.Unlock()
+}
+
+// Origin returns the first frame of the creation stack outside of the async
+// package, formatted as "file:line".
+func (e *Entry) Origin() string {
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, rootPackage) {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// Stack returns the creation stack of the goroutine
+func (e *Entry) Stack() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// Since returns the goroutines registered after the given sequence number
+// that are still running, ordered by their registration.
+func Since(mark uint64) []*Entry {
+	mu.Lock()
+	defer mu.Unlock()
+	var running []*Entry
+	for seq, e := range entries {
+		if seq > mark {
+			running = append(running, e)
+		}
+	}
+	slices.SortFunc(running, func(a, b *Entry) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return running
+}